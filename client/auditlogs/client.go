@@ -0,0 +1,150 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package auditlogs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mendersoftware/mender-cli/client"
+)
+
+const (
+	logsURL = "/api/management/v1/auditlogs/logs"
+
+	logsPerPage = 100
+
+	// audit log actions of the remote terminal sessions
+	ActionOpenTerminal  = "open_terminal"
+	ActionCloseTerminal = "close_terminal"
+
+	objectTypeDevice = "device"
+)
+
+type Actor struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Email string `json:"email,omitempty"`
+}
+
+type Object struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type LogEntry struct {
+	Actor  Actor               `json:"actor"`
+	Time   time.Time           `json:"time"`
+	Action string              `json:"action"`
+	Object Object              `json:"object"`
+	Change string              `json:"change,omitempty"`
+	Meta   map[string][]string `json:"meta,omitempty"`
+}
+
+// TerminalSession is a remote terminal session, as recorded in the audit
+// logs
+type TerminalSession struct {
+	ID        string
+	DeviceID  string
+	User      string
+	StartTime time.Time
+	// EndTime is zero if the session is still open
+	EndTime time.Time
+}
+
+type Client struct {
+	url     string
+	logsURL string
+	client  *http.Client
+}
+
+func NewClient(url string, skipVerify bool) *Client {
+	return &Client{
+		url:     url,
+		logsURL: client.JoinURL(url, logsURL),
+		client:  client.NewHttpClient(skipVerify),
+	}
+}
+
+// ListDeviceLogs returns all the audit log entries of a device
+func (c *Client) ListDeviceLogs(token, deviceID string) ([]LogEntry, error) {
+	entries := []LogEntry{}
+	for page := 1; ; page++ {
+		q := url.Values{
+			"object_type": []string{objectTypeDevice},
+			"object_id":   []string{deviceID},
+			"per_page":    []string{strconv.Itoa(logsPerPage)},
+			"page":        []string{strconv.Itoa(page)},
+		}
+		body, err := client.DoGetRequest(token, c.logsURL+"?"+q.Encode(), c.client)
+		if err != nil {
+			return nil, err
+		}
+
+		var list []LogEntry
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, err
+		}
+		// the server may return fewer entries per page than requested,
+		// so only an empty page is the last one
+		if len(list) == 0 {
+			return entries, nil
+		}
+		entries = append(entries, list...)
+	}
+}
+
+// ListTerminalSessions returns the remote terminal sessions of a device,
+// the most recent first
+func (c *Client) ListTerminalSessions(token, deviceID string) ([]TerminalSession, error) {
+	entries, err := c.ListDeviceLogs(token, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []TerminalSession{}
+	index := map[string]int{}
+	session := func(id string) *TerminalSession {
+		i, ok := index[id]
+		if !ok {
+			i = len(sessions)
+			index[id] = i
+			sessions = append(sessions, TerminalSession{ID: id, DeviceID: deviceID})
+		}
+		return &sessions[i]
+	}
+	for _, entry := range entries {
+		for _, sessionID := range entry.Meta["session_id"] {
+			switch entry.Action {
+			case ActionOpenTerminal:
+				s := session(sessionID)
+				s.StartTime = entry.Time
+				s.User = entry.Actor.Email
+				if s.User == "" {
+					s.User = entry.Actor.ID
+				}
+			case ActionCloseTerminal:
+				session(sessionID).EndTime = entry.Time
+			}
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].StartTime.After(sessions[j].StartTime)
+	})
+	return sessions, nil
+}
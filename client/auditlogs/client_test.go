@@ -0,0 +1,79 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package auditlogs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestListTerminalSessions(t *testing.T) {
+	t.Parallel()
+	logs := `[
+  {"actor": {"id": "u1", "type": "user", "email": "user@example.com"},
+   "time": "2025-01-02T10:05:00Z", "action": "close_terminal",
+   "object": {"id": "device-1", "type": "device"},
+   "meta": {"session_id": ["s2"]}},
+  {"actor": {"id": "u1", "type": "user", "email": "user@example.com"},
+   "time": "2025-01-02T10:00:00Z", "action": "open_terminal",
+   "object": {"id": "device-1", "type": "device"},
+   "meta": {"session_id": ["s2"]}},
+  {"actor": {"id": "u2", "type": "user"},
+   "time": "2025-01-01T10:00:00Z", "action": "open_terminal",
+   "object": {"id": "device-1", "type": "device"},
+   "meta": {"session_id": ["s1"]}},
+  {"actor": {"id": "u2", "type": "user"},
+   "time": "2025-01-01T09:00:00Z", "action": "update_device",
+   "object": {"id": "device-1", "type": "device"}}
+]`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != logsURL || q.Get("object_id") != "device-1" ||
+			q.Get("object_type") != "device" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// the server returns fewer entries per page than requested
+		const perPage = 2
+		var entries []json.RawMessage
+		_ = json.Unmarshal([]byte(logs), &entries)
+		page, _ := strconv.Atoi(q.Get("page"))
+		start := min((page-1)*perPage, len(entries))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(entries[start:min(start+perPage, len(entries))])
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, true)
+	sessions, err := client.ListTerminalSessions("token", "device-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+	}
+	if sessions[0].ID != "s2" || sessions[0].User != "user@example.com" ||
+		sessions[0].EndTime.Sub(sessions[0].StartTime) != 5*time.Minute {
+		t.Errorf("Unexpected session: %+v", sessions[0])
+	}
+	if sessions[1].ID != "s1" || sessions[1].User != "u2" || !sessions[1].EndTime.IsZero() {
+		t.Errorf("Unexpected session: %+v", sessions[1])
+	}
+}
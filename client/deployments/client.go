@@ -39,9 +39,16 @@ import (
 
 const (
 	httpErrorBoundary = 300
+
+	listPerPage = 100
 )
 
-type artifactData struct {
+// ErrArtifactExists is returned when uploading an artifact whose name and
+// device types conflict with an artifact of the server
+var ErrArtifactExists = errors.New("Artifact with same name or depends exists already")
+
+// ArtifactData is an artifact as listed by the server
+type ArtifactData struct {
 	ID                    string   `json:"id"`
 	Description           string   `json:"description"`
 	Name                  string   `json:"name"`
@@ -81,6 +88,10 @@ const (
 	transferCompleteURL = "/api/management/v1/deployments/artifacts/directupload/:id/complete"
 	artifactURL         = "/api/management/v1/deployments/artifacts/:id"
 	artifactDownloadURL = "/api/management/v1/deployments/artifacts/:id/download"
+	deploymentsURL      = "/api/management/v1/deployments/deployments"
+	releasesURL         = "/api/management/v2/deployments/deployments/releases"
+	releaseURL          = "/api/management/v2/deployments/deployments/releases/:name"
+	releaseTagsURL      = "/api/management/v2/deployments/deployments/releases/:name/tags"
 )
 
 type Client struct {
@@ -91,6 +102,10 @@ type Client struct {
 	artifactsListURL    string
 	artifactDeleteURL   string
 	directUploadURL     string
+	deploymentsURL      string
+	releasesURL         string
+	releaseURL          string
+	releaseTagsURL      string
 	client              *http.Client
 }
 
@@ -100,7 +115,8 @@ type Link struct {
 }
 
 type UploadLink struct {
-	ArtifactID string `json:"id"`
+	ArtifactID string    `json:"id"`
+	Expire     time.Time `json:"expire"`
 
 	Link
 }
@@ -114,6 +130,10 @@ func NewClient(url string, skipVerify bool) *Client {
 		artifactsListURL:    client.JoinURL(url, artifactsListURL),
 		artifactDeleteURL:   client.JoinURL(url, artifactsDeleteURL),
 		directUploadURL:     client.JoinURL(url, directUploadURL),
+		deploymentsURL:      client.JoinURL(url, deploymentsURL),
+		releasesURL:         client.JoinURL(url, releasesURL),
+		releaseURL:          client.JoinURL(url, releaseURL),
+		releaseTagsURL:      client.JoinURL(url, releaseTagsURL),
 		client:              client.NewHttpClient(skipVerify),
 	}
 }
@@ -172,7 +192,7 @@ func (c *Client) ListArtifacts(token string, detailLevel, perPage, page int, raw
 			return fmt.Errorf("error reading response body: %w", err)
 		}
 	} else {
-		var list []artifactData
+		var list []ArtifactData
 		err = json.NewDecoder(rsp.Body).Decode(&list)
 		if err != nil {
 			return err
@@ -184,7 +204,31 @@ func (c *Client) ListArtifacts(token string, detailLevel, perPage, page int, raw
 	return nil
 }
 
-func listArtifact(a artifactData, detailLevel int) {
+// ListAllArtifacts returns all the artifacts of the server
+func (c *Client) ListAllArtifacts(token string) ([]ArtifactData, error) {
+	artifacts := []ArtifactData{}
+	for page := 1; ; page++ {
+		q := url.Values{
+			"per_page": []string{strconv.Itoa(listPerPage)},
+			"page":     []string{strconv.Itoa(page)},
+		}
+		body, err := client.DoGetRequest(token, c.artifactsListURL+"?"+q.Encode(), c.client)
+		if err != nil {
+			return nil, err
+		}
+
+		var list []ArtifactData
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, list...)
+		if len(list) < listPerPage {
+			return artifacts, nil
+		}
+	}
+}
+
+func listArtifact(a ArtifactData, detailLevel int) {
 	fmt.Printf("ID: %s\n", a.ID)
 	fmt.Printf("Name: %s\n", a.Name)
 	if detailLevel >= 1 {
@@ -359,22 +403,27 @@ func (c *Client) DirectUpload(
 		return errors.New(
 			fmt.Sprintf("artifact upload to '%s' failed with status %d", req.Host, rsp.StatusCode),
 		)
-	} else {
-		body := readArtifactMetadata(artifactPath, artifactStats.Size())
-		_, err := client.DoPostRequest(
-			token,
-			client.JoinURL(
-				c.url,
-				strings.ReplaceAll(transferCompleteURL, ":id", id),
-			),
-			c.client,
-			body,
-		)
-		if err != nil {
-			return errors.Wrap(err, "failed to notify on complete upload")
-		}
 	}
 
+	return c.completeDirectUpload(token, artifactPath, artifactStats.Size(), id)
+}
+
+// completeDirectUpload notifies the server that the artifact was uploaded
+// to the storage
+func (c *Client) completeDirectUpload(token, artifactPath string, size int64, id string) error {
+	body := readArtifactMetadata(artifactPath, size)
+	_, err := client.DoPostRequest(
+		token,
+		client.JoinURL(
+			c.url,
+			strings.ReplaceAll(transferCompleteURL, ":id", id),
+		),
+		c.client,
+		body,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to notify on complete upload")
+	}
 	return nil
 }
 
@@ -462,7 +511,7 @@ func (c *Client) UploadArtifact(
 			return errors.New("Unauthorized. Please Login first")
 		} else if rsp.StatusCode == http.StatusConflict {
 			log.Verbf("artifact upload to '%s' failed with status %d", req.Host, rsp.StatusCode)
-			return errors.New("Artifact with same name or depends exists already")
+			return ErrArtifactExists
 		}
 		return errors.New(
 			fmt.Sprintf("artifact upload to '%s' failed with status %d", req.Host, rsp.StatusCode),
@@ -511,66 +560,20 @@ func (c *Client) DeleteArtifact(
 	return nil
 }
 
-func (c *Client) DownloadArtifact(
-	sourcePath, artifactID, token string, noProgress bool,
-) error {
-
-	link, err := c.getLink(artifactID, token)
-	if err != nil {
-		return errors.Wrap(err, "Cannot get artifact link")
-	}
-	artifact, err := c.getArtifact(artifactID, token)
-	if err != nil {
-		return errors.Wrap(err, "Cannot get artifact details")
-	}
-	log.Verbf("link: \n%v\n", link.Uri)
-	log.Verbf("artifact: \n%v\n", artifact.Size)
-
-	if sourcePath != "" {
-		sourcePath += "/"
-	}
-	sourcePath += artifact.Name + ".mender"
-
-	req, err := http.NewRequest(http.MethodGet, link.Uri, nil)
-	if err != nil {
-		return errors.Wrap(err, "Cannot create request")
-	}
-
-	reqDump, _ := httputil.DumpRequest(req, false)
-	log.Verbf("sending request: \n%v", string(reqDump))
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return errors.Wrap(err, "GET /artifacts request failed")
-	}
-	defer resp.Body.Close()
-
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return c.downloadFile(artifact.Size, sourcePath, resp, noProgress)
-	case http.StatusBadRequest:
-		return errors.New("Bad request\n")
-	case http.StatusForbidden:
-		return errors.New("Forbidden")
-	case http.StatusNotFound:
-		return errors.New("File not found on the device\n")
-	case http.StatusConflict:
-		return errors.New("The device is not connected\n")
-	case http.StatusInternalServerError:
-		return errors.New("Internal server error\n")
-	default:
-		return errors.New("Error: Received unexpected response code: " +
-			strconv.Itoa(resp.StatusCode))
-	}
-}
-
 type DownloadLink struct {
 	Uri    string    `json:"uri"`
 	Expire time.Time `json:"expire"`
 }
 
 type Artifact struct {
-	Size int64  `json:"size"`
-	Name string `json:"name"`
+	Size    int64  `json:"size"`
+	Name    string `json:"name"`
+	Updates []struct {
+		Files []struct {
+			Name     string `json:"name"`
+			Checksum string `json:"checksum"`
+		} `json:"files"`
+	} `json:"updates"`
 }
 
 func (c *Client) getArtifact(
@@ -645,71 +648,6 @@ func (c *Client) getLink(
 	return &link, nil
 }
 
-func (c *Client) downloadFile(size int64, localFileName string, resp *http.Response,
-	noProgress bool) error {
-	path := resp.Header.Get("X-MEN-FILE-PATH")
-	uid := resp.Header.Get("X-MEN-FILE-UID")
-	gid := resp.Header.Get("X-MEN-FILE-GID")
-	var n int64
-
-	file, err := os.Create(localFileName)
-	if err != nil {
-		return errors.Wrap(err, "Cannot create file")
-	}
-	defer file.Close()
-	if err != nil {
-		log.Errf("Failed to create the file %s locally\n", path)
-		return err
-	}
-
-	if resp.Header.Get("Content-Type") != "application/vnd.mender-artifact" {
-		return fmt.Errorf("Unexpected Content-Type header: %s", resp.Header.Get("Content-Type"))
-	}
-	if err != nil {
-		log.Err("downloadFile: Failed to parse the Content-Type header")
-		return err
-	}
-	i, _ := strconv.Atoi(resp.Header.Get("Content-Length"))
-	sourceSize := int64(i)
-	source := resp.Body
-
-	if !noProgress {
-		var bar *pb.ProgressBar = pb.New64(sourceSize).
-			Set(pb.Bytes, true).
-			SetRefreshRate(time.Millisecond * 100)
-		bar.Start()
-		// create proxy reader
-		reader := bar.NewProxyReader(source)
-		n, err = io.Copy(file, reader)
-	} else {
-		n, err = io.Copy(file, source)
-	}
-	log.Verbf("wrote: %d\n", n)
-	if err != nil {
-		return err
-	}
-	if n != size {
-		return errors.New(
-			"The downloaded file does not match the expected length in 'X-MEN-FILE-SIZE'",
-		)
-	}
-	// Set the proper permissions and {G,U}ID's if present
-	if uid != "" && gid != "" {
-		uidi, err := strconv.Atoi(uid)
-		if err != nil {
-			return err
-		}
-		gidi, err := strconv.Atoi(gid)
-		if err != nil {
-			return err
-		}
-		err = os.Chown(file.Name(), uidi, gidi)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
 func checkArtifactFormat(artifact *os.File) error {
 	tr := tar.NewReader(artifact)
 	versionH, err := tr.Next()
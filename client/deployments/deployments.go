@@ -0,0 +1,67 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package deployments
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/mendersoftware/mender-cli/client"
+)
+
+const (
+	// statuses of the deployments which are not finished
+	DeploymentStatusPending    = "pending"
+	DeploymentStatusInProgress = "inprogress"
+)
+
+// Deployment is a deployment of an artifact to devices
+type Deployment struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	ArtifactName string `json:"artifact_name"`
+	Status       string `json:"status"`
+	// Artifacts are the IDs of the artifacts used by the deployment
+	Artifacts []string `json:"artifacts"`
+}
+
+// ListActiveDeployments returns the deployments which are pending or in
+// progress
+func (c *Client) ListActiveDeployments(token string) ([]Deployment, error) {
+	deployments := []Deployment{}
+	for _, status := range []string{DeploymentStatusPending, DeploymentStatusInProgress} {
+		for page := 1; ; page++ {
+			q := url.Values{
+				"status":   []string{status},
+				"per_page": []string{strconv.Itoa(listPerPage)},
+				"page":     []string{strconv.Itoa(page)},
+			}
+			body, err := client.DoGetRequest(token, c.deploymentsURL+"?"+q.Encode(), c.client)
+			if err != nil {
+				return nil, err
+			}
+
+			var list []Deployment
+			if err := json.Unmarshal(body, &list); err != nil {
+				return nil, err
+			}
+			deployments = append(deployments, list...)
+			if len(list) < listPerPage {
+				break
+			}
+		}
+	}
+	return deployments, nil
+}
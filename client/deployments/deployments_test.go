@@ -0,0 +1,64 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package deployments
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestListActiveDeployments(t *testing.T) {
+	t.Parallel()
+	counts := map[string]int{
+		DeploymentStatusPending:    2,
+		DeploymentStatusInProgress: listPerPage + 1,
+		"finished":                 5,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != deploymentsURL {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		status := r.URL.Query().Get("status")
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		list := []Deployment{}
+		for i := (page - 1) * perPage; i < page*perPage && i < counts[status]; i++ {
+			list = append(list, Deployment{ID: fmt.Sprintf("%s-%d", status, i), Status: status})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(list)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, true)
+	deployments, err := client.ListActiveDeployments("token")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	expected := counts[DeploymentStatusPending] + counts[DeploymentStatusInProgress]
+	if len(deployments) != expected {
+		t.Errorf("Expected %d deployments, got %d", expected, len(deployments))
+	}
+	for _, d := range deployments {
+		if d.Status == "finished" {
+			t.Errorf("Unexpected finished deployment %s", d.ID)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package deployments
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-cli/log"
+)
+
+const (
+	// the artifact is downloaded to this file next to the destination, and
+	// renamed once complete and verified
+	partialDownloadSuffix = ".part"
+
+	// number of attempts to download an artifact before giving up; each
+	// attempt resumes the download where the previous one stopped
+	downloadAttempts = 3
+
+	// a download link is fetched again if it expires sooner than this
+	downloadLinkExpireMargin = 30 * time.Second
+)
+
+// errLinkExpired is returned when the storage refuses a download link
+// which expired, or whose expiry is unknown
+var errLinkExpired = errors.New("the download link expired")
+
+// DownloadArtifact downloads an artifact to the sourcePath directory,
+// returning the path of the downloaded file. An interrupted download is
+// resumed where it stopped, also by running it again, and the payloads of
+// the downloaded artifact are verified against the checksums known by the
+// server. With a verifyKey, the signature of the artifact is verified too,
+// before the artifact is saved at the returned path.
+func (c *Client) DownloadArtifact(
+	sourcePath, artifactID, token string, noProgress bool, verifyKey []byte,
+) (string, error) {
+	artifact, err := c.getArtifact(artifactID, token)
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot get artifact details")
+	}
+	log.Verbf("artifact: \n%v\n", artifact.Size)
+
+	if sourcePath != "" {
+		sourcePath += "/"
+	}
+	sourcePath += artifact.Name + ".mender"
+	partialPath := sourcePath + partialDownloadSuffix
+
+	if err := c.downloadWithResume(artifactID, token, artifact.Size, partialPath,
+		noProgress); err != nil {
+		return "", err
+	}
+	if err := verifyDownloadedArtifact(partialPath, artifact, verifyKey); err != nil {
+		_ = os.Remove(partialPath)
+		return "", err
+	}
+	if err := os.Rename(partialPath, sourcePath); err != nil {
+		return "", errors.Wrap(err, "Cannot create file")
+	}
+	return sourcePath, nil
+}
+
+// downloadWithResume downloads the artifact to partialPath, resuming the
+// download after the network errors and fetching a new link when the
+// current one expires
+func (c *Client) downloadWithResume(
+	artifactID, token string,
+	size int64,
+	partialPath string,
+	noProgress bool,
+) error {
+	var link *DownloadLink
+	for attempt := 1; ; attempt++ {
+		if link == nil || (!link.Expire.IsZero() &&
+			time.Until(link.Expire) < downloadLinkExpireMargin) {
+			newLink, err := c.getLink(artifactID, token)
+			if err != nil {
+				return errors.Wrap(err, "Cannot get artifact link")
+			}
+			log.Verbf("link: \n%v\n", newLink.Uri)
+			link = newLink
+		}
+
+		resumable, err := c.downloadFile(link, size, partialPath, noProgress)
+		if err == nil {
+			return nil
+		} else if !resumable {
+			return err
+		} else if attempt == downloadAttempts {
+			return errors.Wrap(err, "download interrupted, run the download again to resume it")
+		}
+		if err == errLinkExpired {
+			link = nil
+		}
+		log.Infof("download interrupted: %s, resuming.\n", err.Error())
+	}
+}
+
+// downloadFile downloads the artifact from the link to localFileName,
+// resuming from the end of the file if it already exists. The returned
+// boolean tells whether the download can be resumed after the error.
+func (c *Client) downloadFile(
+	link *DownloadLink,
+	size int64,
+	localFileName string,
+	noProgress bool,
+) (bool, error) {
+	var offset int64
+	if info, err := os.Stat(localFileName); err == nil {
+		offset = info.Size()
+	}
+	if offset > size {
+		log.Verbf("discarding the partial download: larger than the artifact")
+		offset = 0
+	} else if offset == size && size > 0 {
+		return false, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, link.Uri, nil)
+	if err != nil {
+		return false, errors.Wrap(err, "Cannot create request")
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	reqDump, _ := httputil.DumpRequest(req, false)
+	log.Verbf("sending request: \n%v", string(reqDump))
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return true, errors.Wrap(err, "GET /artifacts request failed")
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if offset > 0 {
+			log.Verbf("the storage doesn't support resuming, restarting the download")
+		}
+		offset = 0
+		flags |= os.O_TRUNC
+	case http.StatusPartialContent:
+		log.Infof("resuming the download at %d bytes.\n", offset)
+		flags |= os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the partial download doesn't match the artifact, start over
+		_ = os.Remove(localFileName)
+		return true, errors.New("Invalid partial download")
+	case http.StatusForbidden:
+		// a link which hasn't expired yet is refused for another reason,
+		// which a new link wouldn't fix
+		if link.Expire.IsZero() || !time.Now().Before(link.Expire) {
+			return true, errLinkExpired
+		}
+		return false, errors.New("Access to the artifact denied by the storage")
+	default:
+		return false, downloadStatusError(resp.StatusCode)
+	}
+
+	if resp.Header.Get("Content-Type") != "application/vnd.mender-artifact" {
+		return false, fmt.Errorf(
+			"Unexpected Content-Type header: %s", resp.Header.Get("Content-Type"))
+	}
+
+	file, err := os.OpenFile(localFileName, flags, 0644)
+	if err != nil {
+		return false, errors.Wrap(err, "Cannot create file")
+	}
+	defer file.Close()
+
+	var source io.Reader = resp.Body
+	if !noProgress {
+		bar := pb.New64(size).
+			Set(pb.Bytes, true).
+			SetCurrent(offset).
+			SetRefreshRate(time.Millisecond * 100)
+		bar.Start()
+		defer bar.Finish()
+		source = bar.NewProxyReader(source)
+	}
+	n, err := io.Copy(file, source)
+	log.Verbf("wrote: %d\n", n)
+	if err != nil {
+		return true, err
+	}
+	if offset+n != size {
+		return true, errors.New(
+			"The downloaded file does not match the expected length of the artifact",
+		)
+	}
+	return false, nil
+}
+
+func downloadStatusError(statusCode int) error {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return errors.New("Bad request\n")
+	case http.StatusNotFound:
+		return errors.New("File not found on the device\n")
+	case http.StatusConflict:
+		return errors.New("The device is not connected\n")
+	case http.StatusInternalServerError:
+		return errors.New("Internal server error\n")
+	default:
+		return errors.New("Error: Received unexpected response code: " +
+			strconv.Itoa(statusCode))
+	}
+}
+
+// verifyDownloadedArtifact reads the downloaded artifact, which verifies
+// the payloads against the checksums of its manifest, and its signature
+// with verifyKey if not nil, and compares these checksums to the ones known
+// by the server
+func verifyDownloadedArtifact(path string, artifact *Artifact, verifyKey []byte) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "Cannot open artifact file")
+	}
+	defer f.Close()
+
+	ar, verifier, err := newArtifactReader(f, verifyKey)
+	if err != nil {
+		return err
+	}
+	if err := ar.ReadArtifact(); err != nil {
+		if verifier.Failed(err) {
+			return errors.Wrap(err, "Artifact signature verification failed")
+		}
+		return errors.Wrap(err, "The downloaded artifact is corrupted")
+	}
+	checksums := map[string]string{}
+	for _, handler := range ar.GetHandlers() {
+		for _, file := range handler.GetUpdateAllFiles() {
+			checksums[file.Name] = string(file.Checksum)
+		}
+	}
+	return checkPayloadChecksums(artifact, checksums)
+}
+
+// checkPayloadChecksums compares the checksums of the payload files of an
+// artifact, by name, to the ones known by the server
+func checkPayloadChecksums(artifact *Artifact, checksums map[string]string) error {
+	count := 0
+	for _, update := range artifact.Updates {
+		for _, file := range update.Files {
+			count++
+			checksum, ok := checksums[file.Name]
+			if !ok {
+				return errors.Errorf(
+					"The downloaded artifact is missing the payload file %s", file.Name)
+			} else if checksum != file.Checksum {
+				return errors.Errorf(
+					"Checksum mismatch of the payload file %s: expected %s, got %s",
+					file.Name, file.Checksum, checksum)
+			}
+		}
+	}
+	if count != len(checksums) {
+		return errors.Errorf("The downloaded artifact has %d payload files, expected %d",
+			len(checksums), count)
+	}
+	return nil
+}
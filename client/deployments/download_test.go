@@ -0,0 +1,165 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package deployments
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDownloadWithResume(t *testing.T) {
+	t.Parallel()
+	data := bytes.Repeat([]byte("mender"), 1000)
+	var mutex sync.Mutex
+	links, ranges := 0, []string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/download"):
+			links++
+			link := DownloadLink{
+				Uri: "http://" + r.Host + "/storage?link=" + strconv.Itoa(links),
+			}
+			// the expiry of the first link is unknown
+			if links > 1 {
+				link.Expire = time.Now().Add(time.Hour)
+			}
+			_ = json.NewEncoder(w).Encode(link)
+		case r.URL.Path == "/storage":
+			// the first link expired before it is used
+			if r.URL.Query().Get("link") == "1" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			ranges = append(ranges, r.Header.Get("Range"))
+			w.Header().Set("Content-Type", "application/vnd.mender-artifact")
+			http.ServeContent(w, r, "artifact.mender", time.Time{}, bytes.NewReader(data))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	// the first half was downloaded before the interruption
+	partialPath := filepath.Join(t.TempDir(), "artifact.mender"+partialDownloadSuffix)
+	if err := os.WriteFile(partialPath, data[:len(data)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(srv.URL, true)
+	err := client.downloadWithResume("1234", "token", int64(len(data)), partialPath, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if links != 2 {
+		t.Errorf("Expected a new link after the expiration, got %d links", links)
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=3000-" {
+		t.Errorf("Unexpected ranges: %v", ranges)
+	}
+	downloaded, _ := os.ReadFile(partialPath)
+	if !bytes.Equal(downloaded, data) {
+		t.Errorf("Unexpected download: %d bytes, expected %d", len(downloaded), len(data))
+	}
+}
+
+func TestCheckPayloadChecksums(t *testing.T) {
+	t.Parallel()
+	artifact := &Artifact{}
+	err := json.Unmarshal([]byte(`{"updates":[{"files":[
+		{"name":"rootfs.ext4","checksum":"abcd"},
+		{"name":"config.tar","checksum":"ef01"}
+	]}]}`), artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		checksums map[string]string
+		err       string
+	}{
+		"ok": {
+			checksums: map[string]string{"rootfs.ext4": "abcd", "config.tar": "ef01"},
+		},
+		"mismatch": {
+			checksums: map[string]string{"rootfs.ext4": "abcd", "config.tar": "0000"},
+			err:       "Checksum mismatch of the payload file config.tar",
+		},
+		"missing": {
+			checksums: map[string]string{"rootfs.ext4": "abcd"},
+			err:       "missing the payload file config.tar",
+		},
+		"extra": {
+			checksums: map[string]string{
+				"rootfs.ext4": "abcd",
+				"config.tar":  "ef01",
+				"extra":       "2345",
+			},
+			err: "has 3 payload files, expected 2",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := checkPayloadChecksums(artifact, tc.checksums)
+			if tc.err == "" && err != nil {
+				t.Fatalf("Unexpected error: %s", err.Error())
+			} else if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+				t.Errorf("Expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestDownloadWithResumeForbidden(t *testing.T) {
+	t.Parallel()
+	links := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/download"):
+			links++
+			link := DownloadLink{
+				Uri:    "http://" + r.Host + "/storage",
+				Expire: time.Now().Add(time.Hour),
+			}
+			_ = json.NewEncoder(w).Encode(link)
+		default:
+			// the link is refused before its expiry
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	partialPath := filepath.Join(t.TempDir(), "artifact.mender"+partialDownloadSuffix)
+	client := NewClient(srv.URL, true)
+	err := client.downloadWithResume("1234", "token", 1000, partialPath, true)
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	} else if err == errLinkExpired {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if links != 1 {
+		t.Errorf("Expected a single link, got %d links", links)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package deployments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-cli/log"
+)
+
+// Release is a group of artifacts sharing the same name
+type Release struct {
+	Name           string            `json:"name"`
+	Modified       time.Time         `json:"modified"`
+	Artifacts      []ReleaseArtifact `json:"artifacts"`
+	ArtifactsCount int               `json:"artifacts_count"`
+	Tags           []string          `json:"tags"`
+	Notes          string            `json:"notes"`
+}
+
+// ReleaseArtifact is an artifact of a release
+type ReleaseArtifact struct {
+	ID                    string    `json:"id"`
+	Name                  string    `json:"name"`
+	Description           string    `json:"description"`
+	DeviceTypesCompatible []string  `json:"device_types_compatible"`
+	Signed                bool      `json:"signed"`
+	Size                  int64     `json:"size"`
+	Modified              time.Time `json:"modified"`
+	Updates               []struct {
+		TypeInfo struct {
+			Type string `json:"type"`
+		} `json:"type_info"`
+	} `json:"updates"`
+}
+
+// ReleasesFilter selects the releases returned by ListReleases
+type ReleasesFilter struct {
+	// Name matches the releases whose name starts with it
+	Name string
+	Tag  string
+
+	Page    int
+	PerPage int
+}
+
+// ListReleases returns a page of the releases matching the filter
+func (c *Client) ListReleases(token string, filter ReleasesFilter) ([]Release, error) {
+	q := url.Values{
+		"page":     []string{strconv.Itoa(filter.Page)},
+		"per_page": []string{strconv.Itoa(filter.PerPage)},
+	}
+	if filter.Name != "" {
+		q.Set("name", filter.Name)
+	}
+	if filter.Tag != "" {
+		q.Set("tag", filter.Tag)
+	}
+	body, err := c.doReleasesRequest(token, http.MethodGet, c.releasesURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []Release
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, errors.Wrap(err, "Invalid list of releases")
+	}
+	return releases, nil
+}
+
+// GetRelease returns a release and its artifacts
+func (c *Client) GetRelease(token, name string) (*Release, error) {
+	body, err := c.doReleasesRequest(token, http.MethodGet, releaseNameURL(c.releaseURL, name),
+		nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, errors.Wrap(err, "Invalid release")
+	}
+	return &release, nil
+}
+
+// SetReleaseTags replaces the tags of a release; an empty list removes
+// all the tags
+func (c *Client) SetReleaseTags(token, name string, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+	_, err := c.doReleasesRequest(token, http.MethodPut, releaseNameURL(c.releaseTagsURL, name),
+		tags)
+	return err
+}
+
+// UpdateReleaseNotes replaces the notes of a release
+func (c *Client) UpdateReleaseNotes(token, name, notes string) error {
+	_, err := c.doReleasesRequest(token, http.MethodPatch, releaseNameURL(c.releaseURL, name),
+		map[string]string{"notes": notes})
+	return err
+}
+
+// DeleteReleases deletes releases and all their artifacts; the server
+// refuses to delete the releases used by active deployments
+func (c *Client) DeleteReleases(token string, names []string) error {
+	q := url.Values{"name": names}
+	_, err := c.doReleasesRequest(token, http.MethodDelete, c.releasesURL+"?"+q.Encode(), nil)
+	return err
+}
+
+func releaseNameURL(template, name string) string {
+	return strings.ReplaceAll(template, ":name", url.PathEscape(name))
+}
+
+// doReleasesRequest sends a request to the releases API, with the body
+// encoded as JSON if not nil, and returns the body of the response
+func (c *Client) doReleasesRequest(
+	token, method, reqURL string,
+	body interface{},
+) ([]byte, error) {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, reqURL, reqBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot create request")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	reqDump, _ := httputil.DumpRequest(req, true)
+	log.Verbf("sending request: \n%v", string(reqDump))
+
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s request failed", method, req.URL.Path)
+	}
+	defer rsp.Body.Close()
+
+	rspDump, _ := httputil.DumpResponse(rsp, true)
+	log.Verbf("response: \n%v\n", string(rspDump))
+
+	rspBody, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't read response body")
+	}
+	if rsp.StatusCode >= httpErrorBoundary {
+		return nil, releasesRequestError(req, rsp.StatusCode, rspBody)
+	}
+	return rspBody, nil
+}
+
+// releasesRequestError returns the error of a failed request, with the
+// reason given by the server if any
+func releasesRequestError(req *http.Request, statusCode int, body []byte) error {
+	if statusCode == http.StatusUnauthorized {
+		return errors.New("Unauthorized. Please Login first")
+	}
+	apiErr := struct {
+		Error string `json:"error"`
+	}{}
+	msg := fmt.Sprintf("%s %s request failed with status %d", req.Method, req.URL.Path,
+		statusCode)
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+		msg += ": " + apiErr.Error
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package deployments
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReleases(t *testing.T) {
+	t.Parallel()
+	var mutex sync.Mutex
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.RequestURI()+" "+string(body))
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == releasesURL:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"name":"release-1","artifacts_count":2,"tags":["prod"]}]`))
+		case r.Method == http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"release not found","request_id":"1234"}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, true)
+	releases, err := client.ListReleases("token", ReleasesFilter{
+		Tag:     "prod",
+		Page:    2,
+		PerPage: 10,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if len(releases) != 1 || releases[0].Name != "release-1" ||
+		releases[0].ArtifactsCount != 2 {
+		t.Errorf("Unexpected releases: %v", releases)
+	}
+
+	_, err = client.GetRelease("token", "release 2")
+	if err == nil || !strings.Contains(err.Error(), "status 404: release not found") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := client.SetReleaseTags("token", "release-1", nil); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if err := client.UpdateReleaseNotes("token", "release-1", "Fixes"); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if err := client.DeleteReleases("token", []string{"release-1", "release-2"}); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	expected := []string{
+		"GET " + releasesURL + "?page=2&per_page=10&tag=prod ",
+		"GET " + releasesURL + "/release%202 ",
+		"PUT " + releasesURL + "/release-1/tags []",
+		"PATCH " + releasesURL + `/release-1 {"notes":"Fixes"}`,
+		"DELETE " + releasesURL + "?name=release-1&name=release-2 ",
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected requests:\n%s", strings.Join(requests, "\n"))
+	}
+}
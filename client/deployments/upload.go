@@ -0,0 +1,346 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package deployments
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-cli/log"
+)
+
+const (
+	// size of the blocks of the chunked uploads
+	uploadBlockSize = 8 * 1024 * 1024
+
+	// an upload link is not reused if it expires sooner than this
+	uploadLinkExpireMargin = 5 * time.Minute
+
+	// the links of the storages supporting the chunked uploads, the Azure
+	// block blobs, require this header
+	headerBlobType = "x-ms-blob-type"
+	blobTypeBlock  = "BlockBlob"
+)
+
+// errUploadLinkRefused is returned when the storage refuses the upload
+// link, usually because it expired
+var errUploadLinkRefused = errors.New("the storage refused the upload link")
+
+// uploadState is the state of a chunked direct upload, saved locally so
+// an interrupted upload can be resumed
+type uploadState struct {
+	Link      UploadLink    `json:"link"`
+	Size      int64         `json:"size"`
+	ModTime   time.Time     `json:"mod_time"`
+	BlockSize int64         `json:"block_size"`
+	Blocks    []uploadBlock `json:"blocks"`
+}
+
+// uploadBlock is a block uploaded to the storage
+type uploadBlock struct {
+	Index int `json:"index"`
+	// Checksum is the base64 encoded MD5 checksum of the block, which is
+	// verified by the storage
+	Checksum string `json:"checksum"`
+}
+
+// supportsChunkedUpload returns true if the upload link accepts the
+// artifact in blocks
+func supportsChunkedUpload(link *UploadLink) bool {
+	for k, v := range link.Header {
+		if strings.EqualFold(k, headerBlobType) && v == blobTypeBlock {
+			return true
+		}
+	}
+	return false
+}
+
+// blockID returns the ID of a block; all the IDs of a blob must have the
+// same length
+func blockID(index int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%08d", index)))
+}
+
+// loadUploadState returns the saved state of the upload of the artifact,
+// if it is still valid; the upload link is tried if its expiry is unknown
+func loadUploadState(statePath string, info os.FileInfo) *uploadState {
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		return nil
+	}
+	state := &uploadState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		log.Verbf("discarding the invalid upload state: %s", err.Error())
+		return nil
+	}
+	if state.Size != info.Size() || !state.ModTime.Equal(info.ModTime()) {
+		log.Verbf("discarding the upload state: the artifact changed")
+		return nil
+	} else if !state.Link.Expire.IsZero() &&
+		time.Until(state.Link.Expire) < uploadLinkExpireMargin {
+		log.Verbf("discarding the upload state: the upload link expired")
+		return nil
+	} else if state.BlockSize <= 0 {
+		return nil
+	}
+	return state
+}
+
+func (s *uploadState) save(statePath string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(statePath), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(statePath, data, 0600)
+}
+
+// ResumableDirectUpload uploads an artifact directly to the storage in
+// blocks, saving the progress in statePath, so that running it again after
+// an interruption uploads only the missing blocks. If the storage doesn't
+// support the chunked uploads, the artifact is uploaded with DirectUpload.
+func (c *Client) ResumableDirectUpload(
+	token, artifactPath, statePath string,
+	noProgress bool,
+) error {
+	artifact, err := os.Open(artifactPath)
+	if err != nil {
+		return errors.Wrap(err, "Cannot read artifact file")
+	}
+	defer artifact.Close()
+
+	info, err := artifact.Stat()
+	if err != nil {
+		return errors.Wrap(err, "Cannot read artifact file stats")
+	}
+	if err = checkArtifactFormat(artifact); err != nil {
+		return err
+	}
+
+	state := loadUploadState(statePath, info)
+	resumed := state != nil
+	if resumed {
+		log.Infof("resuming the upload of the artifact (%d blocks uploaded).\n",
+			len(state.Blocks))
+	} else {
+		log.Infof("getting direct link.\n")
+		link, err := c.DirectDownloadLink(token)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the direct pre-signed URL")
+		}
+		if !supportsChunkedUpload(link) {
+			log.Verbf("the storage doesn't support chunked uploads")
+			_ = os.Remove(statePath)
+			log.Infof("uploading the artifact.\n")
+			return c.DirectUpload(token, artifactPath, link.ArtifactID, link.Uri,
+				link.Header, noProgress)
+		}
+		state = &uploadState{
+			Link:      *link,
+			Size:      info.Size(),
+			ModTime:   info.ModTime(),
+			BlockSize: uploadBlockSize,
+		}
+		if err := state.save(statePath); err != nil {
+			return errors.Wrap(err, "Cannot save the upload state")
+		}
+	}
+
+	log.Infof("uploading the artifact.\n")
+	if err := c.uploadBlocks(artifact, state, statePath, noProgress); err != nil {
+		if resumed && errors.Is(err, errUploadLinkRefused) {
+			// the link of the saved state expired: start over with a new one
+			log.Infof("the upload link expired, restarting the upload.\n")
+			if err := os.Remove(statePath); err != nil {
+				return errors.Wrap(err, "Cannot remove the upload state")
+			}
+			return c.ResumableDirectUpload(token, artifactPath, statePath, noProgress)
+		}
+		return errors.Wrap(err, "upload interrupted, run the upload again to resume it")
+	}
+	if err := c.commitBlocks(state); err != nil {
+		return err
+	}
+	if err := c.completeDirectUpload(token, artifactPath, state.Size,
+		state.Link.ArtifactID); err != nil {
+		return err
+	}
+	_ = os.Remove(statePath)
+	return nil
+}
+
+// uploadBlocks uploads the blocks missing from the state
+func (c *Client) uploadBlocks(
+	artifact *os.File,
+	state *uploadState,
+	statePath string,
+	noProgress bool,
+) error {
+	uploaded := map[int]bool{}
+	for _, block := range state.Blocks {
+		uploaded[block.Index] = true
+	}
+
+	var bar *pb.ProgressBar
+	if !noProgress {
+		bar = pb.New64(state.Size).
+			Set(pb.Bytes, true).
+			SetRefreshRate(time.Millisecond * 100)
+		bar.Start()
+		defer bar.Finish()
+	}
+
+	buf := make([]byte, state.BlockSize)
+	for index := 0; int64(index)*state.BlockSize < state.Size; index++ {
+		offset := int64(index) * state.BlockSize
+		size := state.Size - offset
+		if size > state.BlockSize {
+			size = state.BlockSize
+		}
+		if uploaded[index] {
+			if bar != nil {
+				bar.Add64(size)
+			}
+			continue
+		}
+		data := buf[:size]
+		if _, err := artifact.ReadAt(data, offset); err != nil {
+			return errors.Wrap(err, "Cannot read artifact file")
+		}
+		sum := md5.Sum(data)
+		checksum := base64.StdEncoding.EncodeToString(sum[:])
+		if err := c.putBlock(state.Link, index, data, checksum); err != nil {
+			return err
+		}
+		state.Blocks = append(state.Blocks, uploadBlock{
+			Index:    index,
+			Checksum: checksum,
+		})
+		if err := state.save(statePath); err != nil {
+			return errors.Wrap(err, "Cannot save the upload state")
+		}
+		if bar != nil {
+			bar.Add64(size)
+		}
+	}
+	return nil
+}
+
+// blockURL returns the URL of an operation on the blob of the upload link
+func blockURL(link UploadLink, params url.Values) (string, error) {
+	u, err := url.Parse(link.Uri)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid upload link")
+	}
+	q := u.Query()
+	for k, v := range params {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+func (c *Client) putBlock(link UploadLink, index int, data []byte, checksum string) error {
+	blockURL, err := blockURL(link, url.Values{
+		"comp":    []string{"block"},
+		"blockid": []string{blockID(index)},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, blockURL, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "Cannot create request")
+	}
+	req.Header.Set("Content-MD5", checksum)
+	return c.doBlockRequest(req, link)
+}
+
+// commitBlocks commits the list of the uploaded blocks, in order
+func (c *Client) commitBlocks(state *uploadState) error {
+	blockList := struct {
+		XMLName xml.Name `xml:"BlockList"`
+		Latest  []string `xml:"Latest"`
+	}{}
+	count := int((state.Size + state.BlockSize - 1) / state.BlockSize)
+	for index := 0; index < count; index++ {
+		blockList.Latest = append(blockList.Latest, blockID(index))
+	}
+	body, err := xml.Marshal(blockList)
+	if err != nil {
+		return err
+	}
+	blockListURL, err := blockURL(state.Link, url.Values{
+		"comp": []string{"blocklist"},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, blockListURL,
+		bytes.NewReader(append([]byte(xml.Header), body...)))
+	if err != nil {
+		return errors.Wrap(err, "Cannot create request")
+	}
+	req.Header.Set("x-ms-blob-content-type", "application/vnd.mender-artifact")
+	return c.doBlockRequest(req, state.Link)
+}
+
+func (c *Client) doBlockRequest(req *http.Request, link UploadLink) error {
+	for k, h := range link.Header {
+		// the blob type only applies to the uploads in a single request
+		if !strings.EqualFold(k, headerBlobType) {
+			req.Header.Set(k, h)
+		}
+	}
+
+	reqDump, _ := httputil.DumpRequest(req, false)
+	log.Verbf("sending request: \n%v", string(reqDump))
+
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "PUT request failed")
+	}
+	defer rsp.Body.Close()
+
+	rspDump, _ := httputil.DumpResponse(rsp, true)
+	log.Verbf("response: \n%v\n", string(rspDump))
+
+	if rsp.StatusCode == http.StatusForbidden {
+		_, _ = io.Copy(io.Discard, rsp.Body)
+		return errors.Wrapf(errUploadLinkRefused, "artifact upload to '%s' failed with status %d",
+			req.Host, rsp.StatusCode)
+	} else if rsp.StatusCode >= httpErrorBoundary {
+		_, _ = io.Copy(io.Discard, rsp.Body)
+		return errors.Errorf("artifact upload to '%s' failed with status %d",
+			req.Host, rsp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package deployments
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeTestArtifact writes a file which passes the artifact format check
+func writeTestArtifact(t *testing.T, path string) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	version := []byte(`{"format":"mender","version":3}`)
+	_ = tw.WriteHeader(&tar.Header{Name: "version", Mode: 0644, Size: int64(len(version))})
+	_, _ = tw.Write(version)
+	_ = tw.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResumableDirectUpload(t *testing.T) {
+	t.Parallel()
+	var mutex sync.Mutex
+	blocks := map[string][]byte{}
+	var blob []byte
+	linkRequested, completed := false, false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.URL.Path == directUploadURL:
+			linkRequested = true
+			w.WriteHeader(http.StatusInternalServerError)
+		case r.URL.Path == "/blob" && r.URL.Query().Get("comp") == "block":
+			if r.Header.Get("Content-MD5") == "" || r.Header.Get(headerBlobType) != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			blocks[r.URL.Query().Get("blockid")] = body
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/blob" && r.URL.Query().Get("comp") == "blocklist":
+			blockList := struct {
+				Latest []string `xml:"Latest"`
+			}{}
+			if err := xml.Unmarshal(body, &blockList); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			for _, id := range blockList.Latest {
+				blob = append(blob, blocks[id]...)
+			}
+			w.WriteHeader(http.StatusCreated)
+		case strings.HasSuffix(r.URL.Path, "/complete"):
+			completed = true
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	artifactPath := filepath.Join(dir, "artifact.mender")
+	writeTestArtifact(t, artifactPath)
+	data, _ := os.ReadFile(artifactPath)
+	info, _ := os.Stat(artifactPath)
+
+	// the first block was uploaded before the interruption
+	const blockSize = 1000
+	blocks[blockID(0)] = data[:blockSize]
+	state := &uploadState{
+		Link: UploadLink{
+			ArtifactID: "1234",
+			Expire:     time.Now().Add(time.Hour),
+			Link: Link{
+				Uri:    srv.URL + "/blob?sig=secret",
+				Header: map[string]string{headerBlobType: blobTypeBlock},
+			},
+		},
+		Size:      info.Size(),
+		ModTime:   info.ModTime(),
+		BlockSize: blockSize,
+		Blocks:    []uploadBlock{{Index: 0}},
+	}
+	statePath := filepath.Join(dir, "state.json")
+	if err := state.save(statePath); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(srv.URL, true)
+	err := client.ResumableDirectUpload("token", artifactPath, statePath, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if linkRequested {
+		t.Error("Unexpected request of a new upload link")
+	}
+	if !bytes.Equal(blob, data) {
+		t.Errorf("Unexpected blob: %d bytes, expected %d", len(blob), len(data))
+	}
+	if !completed {
+		t.Error("Expected the upload to be completed")
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Error("Expected the upload state to be removed")
+	}
+}
+
+func TestResumableDirectUploadExpiredLink(t *testing.T) {
+	t.Parallel()
+	var mutex sync.Mutex
+	var blob []byte
+	expiredTried, completed := false, false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.URL.Path == directUploadURL:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"id":"5678","uri":"http://` + r.Host +
+				`/blob?sig=new","header":{"` + headerBlobType + `":"` + blobTypeBlock + `"}}`))
+		case r.URL.Path == "/expired":
+			expiredTried = true
+			w.WriteHeader(http.StatusForbidden)
+		case r.URL.Path == "/blob" && r.URL.Query().Get("comp") == "block":
+			blob = append(blob, body...)
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/blob" && r.URL.Query().Get("comp") == "blocklist":
+			w.WriteHeader(http.StatusCreated)
+		case strings.HasSuffix(r.URL.Path, "/5678/complete"):
+			completed = true
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	artifactPath := filepath.Join(dir, "artifact.mender")
+	writeTestArtifact(t, artifactPath)
+	info, _ := os.Stat(artifactPath)
+
+	// the expiry of the saved link is unknown
+	state := &uploadState{
+		Link: UploadLink{
+			ArtifactID: "1234",
+			Link: Link{
+				Uri:    srv.URL + "/expired?sig=secret",
+				Header: map[string]string{headerBlobType: blobTypeBlock},
+			},
+		},
+		Size:      info.Size(),
+		ModTime:   info.ModTime(),
+		BlockSize: uploadBlockSize,
+	}
+	statePath := filepath.Join(dir, "state.json")
+	if err := state.save(statePath); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(srv.URL, true)
+	err := client.ResumableDirectUpload("token", artifactPath, statePath, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if !expiredTried {
+		t.Error("Expected the saved upload link to be tried")
+	}
+	data, _ := os.ReadFile(artifactPath)
+	if !bytes.Equal(blob, data) {
+		t.Errorf("Unexpected blob: %d bytes, expected %d", len(blob), len(data))
+	}
+	if !completed {
+		t.Error("Expected the upload to be completed")
+	}
+}
+
+func TestResumableDirectUploadFallback(t *testing.T) {
+	t.Parallel()
+	var mutex sync.Mutex
+	var uploaded []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch {
+		case r.URL.Path == directUploadURL:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"id":"1234","uri":"http://` + r.Host + `/s3?sig=secret"}`))
+		case r.URL.Path == "/s3" && r.Method == http.MethodPut:
+			uploaded, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusOK)
+		case strings.HasSuffix(r.URL.Path, "/complete"):
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	artifactPath := filepath.Join(dir, "artifact.mender")
+	writeTestArtifact(t, artifactPath)
+	statePath := filepath.Join(dir, "state.json")
+
+	client := NewClient(srv.URL, true)
+	err := client.ResumableDirectUpload("token", artifactPath, statePath, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	data, _ := os.ReadFile(artifactPath)
+	if !bytes.Equal(uploaded, data) {
+		t.Errorf("Unexpected upload: %d bytes, expected %d", len(uploaded), len(data))
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Error("Unexpected upload state")
+	}
+}
+
+func TestUploadArtifactExists(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer srv.Close()
+
+	artifactPath := filepath.Join(t.TempDir(), "artifact.mender")
+	writeTestArtifact(t, artifactPath)
+
+	client := NewClient(srv.URL, true)
+	err := client.UploadArtifact("", artifactPath, "token", true)
+	if !errors.Is(err, ErrArtifactExists) {
+		t.Errorf("Expected ErrArtifactExists, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package deployments
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-artifact/areader"
+	"github.com/mendersoftware/mender-artifact/artifact"
+
+	"github.com/mendersoftware/mender-cli/log"
+)
+
+// VerifyArtifactSignature verifies the signature of an artifact file with
+// a PEM encoded RSA or ECDSA public key; unsigned artifacts are refused
+func VerifyArtifactSignature(path string, key []byte) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "Cannot open artifact file")
+	}
+	defer f.Close()
+
+	log.Verbf("verifying the signature of the artifact %s", path)
+	ar, _, err := newArtifactReader(f, key)
+	if err != nil {
+		return err
+	}
+	if err := ar.ReadArtifact(); err != nil {
+		return errors.Wrap(err, "Artifact signature verification failed")
+	}
+	return nil
+}
+
+// unsignedArtifactError is the error of the artifact reader when a signed
+// artifact is expected, but it isn't signed
+const unsignedArtifactError = "expecting signed artifact"
+
+// signatureVerifier verifies the signature of an artifact, keeping the
+// verification error to tell it apart from the errors reading the artifact
+type signatureVerifier struct {
+	verify func(message, sig []byte) error
+	err    error
+}
+
+func (v *signatureVerifier) Verify(message, sig []byte) error {
+	v.err = v.verify(message, sig)
+	return v.err
+}
+
+// Failed returns true if err, returned by reading the artifact, is due to
+// a missing or invalid signature
+func (v *signatureVerifier) Failed(err error) bool {
+	if v == nil || err == nil {
+		return false
+	}
+	return v.err != nil || strings.Contains(err.Error(), unsignedArtifactError)
+}
+
+// newArtifactReader returns a reader of the artifact r which verifies its
+// signature with key, refusing unsigned artifacts, or which doesn't verify
+// it if key is nil, in which case the returned verifier is nil
+func newArtifactReader(r io.Reader, key []byte) (*areader.Reader, *signatureVerifier, error) {
+	if key == nil {
+		return areader.NewReader(r), nil, nil
+	}
+	pki, err := artifact.NewPKIVerifier(key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Invalid verification key")
+	}
+	verifier := &signatureVerifier{verify: pki.Verify}
+	ar := areader.NewReaderSigned(r)
+	ar.VerifySignatureCallback = verifier.Verify
+	return ar, verifier, nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package deployments
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mendersoftware/mender-artifact/artifact"
+	"github.com/mendersoftware/mender-artifact/awriter"
+	"github.com/mendersoftware/mender-artifact/handlers"
+)
+
+// generateTestKeys returns a PEM encoded ECDSA private key and its public key
+func generateTestKeys(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	private, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: private}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public})
+}
+
+// writeSignedTestArtifact writes an artifact with a single payload file, signed
+// with signingKey if not nil
+func writeSignedTestArtifact(t *testing.T, signingKey []byte) string {
+	dir := t.TempDir()
+	payload := filepath.Join(dir, "payload")
+	if err := os.WriteFile(payload, []byte("payload"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	update := handlers.NewModuleImage("single-file")
+	if err := update.SetUpdateFiles([]*handlers.DataFile{{Name: payload}}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "test.mender")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var writer *awriter.Writer
+	if signingKey != nil {
+		signer, err := artifact.NewPKISigner(signingKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writer = awriter.NewWriterSigned(f, artifact.NewCompressorGzip(), signer)
+	} else {
+		writer = awriter.NewWriter(f, artifact.NewCompressorGzip())
+	}
+	updateType := "single-file"
+	err = writer.WriteArtifact(&awriter.WriteArtifactArgs{
+		Format:     "mender",
+		Version:    3,
+		Devices:    []string{"raspberrypi4"},
+		Name:       "release-1",
+		Updates:    &awriter.Updates{Updates: []handlers.Composer{update}},
+		Scripts:    &artifact.Scripts{},
+		Provides:   &artifact.ArtifactProvides{ArtifactName: "release-1"},
+		Depends:    &artifact.ArtifactDepends{CompatibleDevices: []string{"raspberrypi4"}},
+		TypeInfoV3: &artifact.TypeInfoV3{Type: &updateType},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVerifyArtifactSignature(t *testing.T) {
+	t.Parallel()
+	signingKey, verifyKey := generateTestKeys(t)
+	_, otherVerifyKey := generateTestKeys(t)
+
+	testCases := map[string]struct {
+		signingKey []byte
+		verifyKey  []byte
+		err        bool
+	}{
+		"signed": {
+			signingKey: signingKey,
+			verifyKey:  verifyKey,
+		},
+		"unsigned": {
+			verifyKey: verifyKey,
+			err:       true,
+		},
+		"wrong key": {
+			signingKey: signingKey,
+			verifyKey:  otherVerifyKey,
+			err:        true,
+		},
+		"invalid key": {
+			signingKey: signingKey,
+			verifyKey:  []byte("not a key"),
+			err:        true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			path := writeSignedTestArtifact(t, tc.signingKey)
+			err := VerifyArtifactSignature(path, tc.verifyKey)
+			if tc.err && err == nil {
+				t.Error("Expected an error, got nil")
+			} else if !tc.err && err != nil {
+				t.Errorf("Unexpected error: %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestVerifyDownloadedArtifactErrors(t *testing.T) {
+	t.Parallel()
+	signingKey, verifyKey := generateTestKeys(t)
+	_, otherVerifyKey := generateTestKeys(t)
+
+	testCases := map[string]struct {
+		signingKey []byte
+		verifyKey  []byte
+		corrupted  bool
+		err        string
+	}{
+		"unsigned": {
+			verifyKey: verifyKey,
+			err:       "Artifact signature verification failed",
+		},
+		"wrong key": {
+			signingKey: signingKey,
+			verifyKey:  otherVerifyKey,
+			err:        "Artifact signature verification failed",
+		},
+		"corrupted": {
+			signingKey: signingKey,
+			verifyKey:  verifyKey,
+			corrupted:  true,
+			err:        "The downloaded artifact is corrupted",
+		},
+		"corrupted, no key": {
+			corrupted: true,
+			err:       "The downloaded artifact is corrupted",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			path := writeSignedTestArtifact(t, tc.signingKey)
+			if tc.corrupted {
+				if err := os.WriteFile(path, []byte("corrupted"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := verifyDownloadedArtifact(path, &Artifact{}, tc.verifyKey)
+			if err == nil {
+				t.Fatal("Expected an error, got nil")
+			} else if !strings.HasPrefix(err.Error(), tc.err) {
+				t.Errorf("Unexpected error: %s, expected: %s", err.Error(), tc.err)
+			}
+		})
+	}
+}
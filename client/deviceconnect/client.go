@@ -14,7 +14,6 @@
 package deviceconnect
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -28,8 +27,10 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/cheggaaa/pb/v3"
 	"github.com/gorilla/websocket"
 	"github.com/mendersoftware/go-lib-micro/ws"
 	"github.com/pkg/errors"
@@ -66,6 +67,10 @@ type Client struct {
 	writeMutex *sync.Mutex
 	token      string
 	client     *http.Client
+	// pingSent is the time the last ping was sent, in nanoseconds since
+	// the epoch, and latency the round-trip time measured with it
+	pingSent atomic.Int64
+	latency  atomic.Int64
 }
 
 func NewClient(url string, token string, skipVerify bool) *Client {
@@ -82,17 +87,16 @@ func NewClient(url string, token string, skipVerify bool) *Client {
 // Connect to the websocket
 func (c *Client) Connect(deviceID string, token string) error {
 	fmt.Fprintf(os.Stderr, "Connecting to the device %s...\n", deviceID)
-	u, err := url.Parse(
-		strings.TrimSuffix(
-			c.url,
-			"/",
-		) + strings.Replace(
-			deviceConnectPath,
-			":deviceID",
-			deviceID,
-			1,
-		),
-	)
+	err := c.dial(strings.Replace(deviceConnectPath, ":deviceID", deviceID, 1), token)
+	if err != nil {
+		return errors.Wrap(err, "Unable to connect to the device")
+	}
+	return nil
+}
+
+// dial connects to the websocket at the given API path
+func (c *Client) dial(path string, token string) error {
+	u, err := url.Parse(strings.TrimSuffix(c.url, "/") + path)
 	if err != nil {
 		return errors.Wrap(err, "Unable to parse the server URL")
 	}
@@ -105,7 +109,7 @@ func (c *Client) Connect(deviceID string, token string) error {
 	}
 	conn, rsp, err := websocket.DefaultDialer.Dial(u.String(), headers)
 	if err != nil {
-		return errors.Wrap(err, "Unable to connect to the device")
+		return err
 	}
 	defer rsp.Body.Close()
 
@@ -142,6 +146,9 @@ func (c *Client) PingPong(ctx context.Context) {
 
 	c.conn.SetPongHandler(func(string) error {
 		ticker.Reset(pingPeriod)
+		if sent := c.pingSent.Load(); sent > 0 {
+			c.latency.Store(time.Now().UnixNano() - sent)
+		}
 		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	})
 
@@ -158,15 +165,13 @@ func (c *Client) PingPong(ctx context.Context) {
 		)
 	})
 
+	// measure the latency right away
+	_ = c.Ping()
+
 	for {
 		select {
 		case <-ticker.C:
-			pongWaitString := strconv.Itoa(int(pongWait.Seconds()))
-			_ = c.conn.WriteControl(
-				websocket.PingMessage,
-				[]byte(pongWaitString),
-				time.Now().Add(writeWait),
-			)
+			_ = c.Ping()
 
 		case <-ctx.Done():
 			return
@@ -174,6 +179,24 @@ func (c *Client) PingPong(ctx context.Context) {
 	}
 }
 
+// Ping sends a ping message; the round-trip time is measured when the pong
+// is received, while PingPong is running
+func (c *Client) Ping() error {
+	pongWaitString := strconv.Itoa(int(pongWait.Seconds()))
+	c.pingSent.Store(time.Now().UnixNano())
+	return c.conn.WriteControl(
+		websocket.PingMessage,
+		[]byte(pongWaitString),
+		time.Now().Add(writeWait),
+	)
+}
+
+// Latency returns the round-trip time measured with the last ping, or zero
+// if no pong was received yet
+func (c *Client) Latency() time.Duration {
+	return time.Duration(c.latency.Load())
+}
+
 // ReadMessage reads a Proto message from the websocket
 func (c *Client) ReadMessage() (*ws.ProtoMsg, error) {
 	c.readMutex.Lock()
@@ -215,9 +238,12 @@ func (c *Client) Close() {
 
 func NewFileTransferClient(url string, token string, skipVerify bool) *Client {
 	return &Client{
-		url:    url,
-		token:  token,
-		client: client.NewHttpClient(skipVerify),
+		url:        url,
+		token:      token,
+		skipVerify: skipVerify,
+		client:     client.NewHttpClient(skipVerify),
+		readMutex:  &sync.Mutex{},
+		writeMutex: &sync.Mutex{},
 	}
 }
 
@@ -255,38 +281,28 @@ func NewDeviceConnectError(errCode int, r io.Reader) *DeviceConnectError {
 	return d
 }
 
-func (c *Client) Upload(sourcePath string, deviceSpec *DeviceSpec) error {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+func (c *Client) Upload(sourcePath string, deviceSpec *DeviceSpec, noProgress bool) error {
 	file, err := os.Open(sourcePath)
 	if err != nil {
 		return err
 	}
-	fi, err := os.Stat(sourcePath)
-	if err != nil {
-		return err
-	}
-	log.Verbf("Uploading the file to %s\n", deviceSpec.DevicePath)
-	if err = writer.WriteField("path", deviceSpec.DevicePath); err != nil {
-		return err
-	}
-	part, err := writer.CreateFormFile("file", sourcePath)
+	fi, err := file.Stat()
 	if err != nil {
+		file.Close()
 		return err
 	}
-	if _, err = io.Copy(part, file); err != nil {
-		return err
-	}
-	if err = writer.WriteField("mode", fmt.Sprintf("%o", fi.Mode())); err != nil {
-		return err
-	}
-	if err = writer.Close(); err != nil {
-		return err
-	}
+
+	// create pipe
+	pR, pW := io.Pipe()
+
+	// create multipart writer
+	writer := multipart.NewWriter(pW)
+
 	req, err := http.NewRequest(http.MethodPut,
 		c.url+fileUploadURL+"devices/"+deviceSpec.DeviceID+"/upload",
-		body)
+		pR)
 	if err != nil {
+		file.Close()
 		return err
 	}
 	req.Header.Set("Content-Type", writer.FormDataContentType())
@@ -295,11 +311,52 @@ func (c *Client) Upload(sourcePath string, deviceSpec *DeviceSpec) error {
 	reqDump, _ := httputil.DumpRequest(req, false)
 	log.Verbf("sending request: \n%v", string(reqDump))
 
+	var bar *pb.ProgressBar
+	if !noProgress {
+		// create progress bar
+		bar = pb.New64(fi.Size()).
+			Set(pb.Bytes, true).
+			SetRefreshRate(time.Millisecond * 100)
+		bar.Start()
+		defer bar.Finish()
+	}
+
+	log.Verbf("Uploading the file to %s\n", deviceSpec.DevicePath)
+	go func() {
+		defer pW.Close()
+		defer file.Close()
+
+		if err := writer.WriteField("path", deviceSpec.DevicePath); err != nil {
+			_ = pW.CloseWithError(err)
+			return
+		}
+		part, err := writer.CreateFormFile("file", sourcePath)
+		if err != nil {
+			_ = pW.CloseWithError(err)
+			return
+		}
+		if !noProgress {
+			part = bar.NewProxyWriter(part)
+		}
+		if _, err = io.Copy(part, file); err != nil {
+			_ = pW.CloseWithError(err)
+			return
+		}
+		if err = writer.WriteField("mode", fmt.Sprintf("%o", fi.Mode())); err != nil {
+			_ = pW.CloseWithError(err)
+			return
+		}
+		if err = writer.Close(); err != nil {
+			_ = pW.CloseWithError(err)
+		}
+	}()
+
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	pR.Close()
 
 	switch resp.StatusCode {
 	case http.StatusCreated:
@@ -321,7 +378,7 @@ func (c *Client) Upload(sourcePath string, deviceSpec *DeviceSpec) error {
 	return NewDeviceConnectError(resp.StatusCode, resp.Body)
 }
 
-func (c *Client) Download(deviceSpec *DeviceSpec, sourcePath string) error {
+func (c *Client) Download(deviceSpec *DeviceSpec, sourcePath string, noProgress bool) error {
 	req, err := http.NewRequest(http.MethodGet,
 		c.url+fileUploadURL+"devices/"+deviceSpec.DeviceID+"/download",
 		nil,
@@ -343,12 +400,14 @@ func (c *Client) Download(deviceSpec *DeviceSpec, sourcePath string) error {
 	}
 	defer resp.Body.Close()
 
-	rspDump, _ := httputil.DumpResponse(resp, true)
+	// the body is not dumped: it is the content of the file, streamed to
+	// disk by downloadFile
+	rspDump, _ := httputil.DumpResponse(resp, false)
 	log.Verbf("Response: \n%v\n", string(rspDump))
 
 	switch resp.StatusCode {
 	case http.StatusOK:
-		return c.downloadFile(sourcePath, resp)
+		return c.downloadFile(sourcePath, resp, noProgress)
 	case http.StatusBadRequest:
 		log.Err("Bad request\n")
 	case http.StatusForbidden:
@@ -366,7 +425,11 @@ func (c *Client) Download(deviceSpec *DeviceSpec, sourcePath string) error {
 	return NewDeviceConnectError(resp.StatusCode, resp.Body)
 }
 
-func (c *Client) downloadFile(localFileName string, resp *http.Response) error {
+func (c *Client) downloadFile(
+	localFileName string,
+	resp *http.Response,
+	noProgress bool,
+) error {
 	path := resp.Header.Get("X-MEN-FILE-PATH")
 	uid := resp.Header.Get("X-MEN-FILE-UID")
 	gid := resp.Header.Get("X-MEN-FILE-GID")
@@ -384,7 +447,11 @@ func (c *Client) downloadFile(localFileName string, resp *http.Response) error {
 		return fmt.Errorf("No proper size given for the file: %s", _size)
 	}
 	var n int64
-	file, err := os.OpenFile(localFileName, os.O_CREATE|os.O_WRONLY, os.FileMode(modeo))
+	file, err := os.OpenFile(
+		localFileName,
+		os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
+		os.FileMode(modeo),
+	)
 	if err != nil {
 		log.Errf("Failed to create the file %s locally\n", path)
 		return err
@@ -398,7 +465,18 @@ func (c *Client) downloadFile(localFileName string, resp *http.Response) error {
 		log.Err("downloadFile: Failed to parse the Content-Type header")
 		return err
 	}
-	n, err = io.Copy(file, resp.Body)
+	if !noProgress {
+		bar := pb.New64(size).
+			Set(pb.Bytes, true).
+			SetRefreshRate(time.Millisecond * 100)
+		bar.Start()
+		// create proxy reader
+		reader := bar.NewProxyReader(resp.Body)
+		n, err = io.Copy(file, reader)
+		bar.Finish()
+	} else {
+		n, err = io.Copy(file, resp.Body)
+	}
 	log.Verbf("wrote: %d\n", n)
 	if err != nil {
 		return err
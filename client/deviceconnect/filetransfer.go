@@ -0,0 +1,223 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package deviceconnect
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mendersoftware/go-lib-micro/ws"
+	wsft "github.com/mendersoftware/go-lib-micro/ws/filetransfer"
+	"github.com/pkg/errors"
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/mendersoftware/mender-cli/log"
+)
+
+var (
+	ErrProtocolNotImplemented = errors.New(
+		"protocol not implemented or enabled on the device",
+	)
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrSizeMismatch     = errors.New("size mismatch")
+)
+
+// FileInfo is the file information reported by the device in response
+// to a stat request
+type FileInfo struct {
+	wsft.FileInfo
+	// Checksum is the hex encoded SHA-256 checksum of the file, if
+	// the device reports it
+	Checksum *string `msgpack:"checksum,omitempty" json:"checksum,omitempty"`
+}
+
+// OpenSession performs the ws protocol handshake and checks that the device
+// accepts the given protocol, returning the session ID
+func (c *Client) OpenSession(proto ws.ProtoType) (string, error) {
+	body, err := msgpack.Marshal(&ws.Open{
+		Versions: []int{ws.ProtocolVersion},
+	})
+	if err != nil {
+		return "", err
+	}
+	m := &ws.ProtoMsg{
+		Header: ws.ProtoHdr{
+			Proto:   ws.ProtoTypeControl,
+			MsgType: ws.MessageTypeOpen,
+		},
+		Body: body,
+	}
+	if err = c.WriteMessage(m); err != nil {
+		return "", err
+	}
+
+	msg, err := c.ReadMessage()
+	if err != nil {
+		return "", err
+	}
+	if msg.Header.MsgType == ws.MessageTypeError {
+		erro := new(ws.Error)
+		_ = msgpack.Unmarshal(msg.Body, erro)
+		return "", errors.Errorf("handshake error from client: %s", erro.Error)
+	} else if msg.Header.MsgType != ws.MessageTypeAccept {
+		return "", ErrProtocolNotImplemented
+	}
+
+	accept := new(ws.Accept)
+	if err = msgpack.Unmarshal(msg.Body, accept); err != nil {
+		return "", err
+	}
+	for _, p := range accept.Protocols {
+		if p == proto {
+			return msg.Header.SessionID, nil
+		}
+	}
+	return "", ErrProtocolNotImplemented
+}
+
+// CloseSession closes the ws session
+func (c *Client) CloseSession(sessionID string) error {
+	m := &ws.ProtoMsg{
+		Header: ws.ProtoHdr{
+			Proto:     ws.ProtoTypeControl,
+			MsgType:   ws.MessageTypeClose,
+			SessionID: sessionID,
+		},
+	}
+	return c.WriteMessage(m)
+}
+
+// StatFile returns the file information for a file on the device
+func (c *Client) StatFile(deviceSpec *DeviceSpec) (*FileInfo, error) {
+	if err := c.Connect(deviceSpec.DeviceID, c.token); err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	sessionID, err := c.OpenSession(ws.ProtoTypeFileTransfer)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = c.CloseSession(sessionID)
+	}()
+
+	body, err := msgpack.Marshal(&wsft.StatFile{
+		Path: &deviceSpec.DevicePath,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = c.WriteMessage(&ws.ProtoMsg{
+		Header: ws.ProtoHdr{
+			Proto:     ws.ProtoTypeFileTransfer,
+			MsgType:   wsft.MessageTypeStat,
+			SessionID: sessionID,
+		},
+		Body: body,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		m, err := c.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case m.Header.Proto == ws.ProtoTypeControl &&
+			m.Header.MsgType == ws.MessageTypePing:
+			err = c.WriteMessage(&ws.ProtoMsg{
+				Header: ws.ProtoHdr{
+					Proto:     ws.ProtoTypeControl,
+					MsgType:   ws.MessageTypePong,
+					SessionID: sessionID,
+				},
+			})
+			if err != nil {
+				return nil, err
+			}
+		case m.Header.Proto == ws.ProtoTypeFileTransfer &&
+			m.Header.MsgType == wsft.MessageTypeFileInfo:
+			fileInfo := new(FileInfo)
+			if err = msgpack.Unmarshal(m.Body, fileInfo); err != nil {
+				return nil, errors.Wrap(err, "Unable to parse the file information")
+			}
+			return fileInfo, nil
+		case m.Header.MsgType == wsft.MessageTypeError:
+			erro := new(wsft.Error)
+			_ = msgpack.Unmarshal(m.Body, erro)
+			if erro.Error != nil {
+				return nil, errors.New(*erro.Error)
+			}
+			return nil, errors.Errorf("Unable to stat the file %s", deviceSpec.DevicePath)
+		}
+	}
+}
+
+// FileChecksum returns the hex encoded SHA-256 checksum of a local file
+func FileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyFile compares a local file with the file information reported by
+// the device, returning ErrSizeMismatch or ErrChecksumMismatch if they
+// differ. The devices running the current mender-connect don't report
+// the checksums, in which case only the size is compared.
+func (c *Client) VerifyFile(localPath string, deviceSpec *DeviceSpec) error {
+	fileInfo, err := c.StatFile(deviceSpec)
+	if err != nil {
+		return errors.Wrap(err, "Unable to stat the file on the device")
+	}
+	fi, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	if fileInfo.Size != nil && *fileInfo.Size != fi.Size() {
+		return errors.Wrap(ErrSizeMismatch, fmt.Sprintf(
+			"size %d on the device, %d locally", *fileInfo.Size, fi.Size(),
+		))
+	}
+	if fileInfo.Checksum == nil {
+		log.Errf("WARNING: the device did not report a checksum for %s: "+
+			"only the size was verified, not the content\n", deviceSpec.DevicePath)
+		return nil
+	}
+	checksum, err := FileChecksum(localPath)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(checksum, *fileInfo.Checksum) {
+		return errors.Wrap(ErrChecksumMismatch, fmt.Sprintf(
+			"sha256 %s on the device, %s locally", *fileInfo.Checksum, checksum,
+		))
+	}
+	log.Verbf("sha256 %s verified\n", checksum)
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package deviceconnect
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/mendersoftware/go-lib-micro/ws"
+	wsft "github.com/mendersoftware/go-lib-micro/ws/filetransfer"
+	"github.com/vmihailenco/msgpack"
+)
+
+func newStatServer(t *testing.T, fileInfo *FileInfo) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err.Error())
+			return
+		}
+		defer conn.Close()
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var m ws.ProtoMsg
+			if err := msgpack.Unmarshal(data, &m); err != nil {
+				t.Errorf("Unexpected error: %s", err.Error())
+				return
+			}
+			rsp := &ws.ProtoMsg{}
+			switch m.Header.MsgType {
+			case ws.MessageTypeOpen:
+				rsp.Header = ws.ProtoHdr{
+					Proto:     ws.ProtoTypeControl,
+					MsgType:   ws.MessageTypeAccept,
+					SessionID: "session",
+				}
+				rsp.Body, _ = msgpack.Marshal(&ws.Accept{
+					Version:   ws.ProtocolVersion,
+					Protocols: []ws.ProtoType{ws.ProtoTypeFileTransfer},
+				})
+			case wsft.MessageTypeStat:
+				rsp.Header = ws.ProtoHdr{
+					Proto:     ws.ProtoTypeFileTransfer,
+					MsgType:   wsft.MessageTypeFileInfo,
+					SessionID: "session",
+				}
+				rsp.Body, _ = msgpack.Marshal(fileInfo)
+			default:
+				continue
+			}
+			data, _ = msgpack.Marshal(rsp)
+			if err := conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+				return
+			}
+		}
+	}))
+}
+
+func TestVerifyFile(t *testing.T) {
+	t.Parallel()
+	localPath := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(localPath, []byte("hello world\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	size := int64(12)
+	checksum := "a948904f2f0f479b8f8197694b30184b0d2ed1c1cd2a1ec0fb85d299a192a447"
+	otherChecksum := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	otherSize := int64(13)
+
+	testCases := map[string]struct {
+		fileInfo FileInfo
+		err      error
+	}{
+		"ok": {
+			fileInfo: FileInfo{
+				FileInfo: wsft.FileInfo{Size: &size},
+				Checksum: &checksum,
+			},
+		},
+		"ok, no checksum": {
+			fileInfo: FileInfo{
+				FileInfo: wsft.FileInfo{Size: &size},
+			},
+		},
+		"error, checksum": {
+			fileInfo: FileInfo{
+				FileInfo: wsft.FileInfo{Size: &size},
+				Checksum: &otherChecksum,
+			},
+			err: ErrChecksumMismatch,
+		},
+		"error, size": {
+			fileInfo: FileInfo{
+				FileInfo: wsft.FileInfo{Size: &otherSize},
+			},
+			err: ErrSizeMismatch,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			path := "/tmp/file"
+			tc.fileInfo.Path = &path
+			srv := newStatServer(t, &tc.fileInfo)
+			defer srv.Close()
+
+			client := NewFileTransferClient(srv.URL, "token", true)
+			err := client.VerifyFile(localPath, &DeviceSpec{
+				DeviceID:   "1234",
+				DevicePath: path,
+			})
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Unexpected error: %v, expected: %v", err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package deviceconnect
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// stat format: raw mode (hex), uid, gid, size, mtime (epoch) and file name
+const statFormat = "%f %u %g %s %Y %n"
+
+// unix file type bits as reported by stat(1)
+const (
+	unixModeTypeMask  = 0170000
+	unixModeSocket    = 0140000
+	unixModeSymlink   = 0120000
+	unixModeBlock     = 0060000
+	unixModeDir       = 0040000
+	unixModeChar      = 0020000
+	unixModeNamedPipe = 0010000
+	unixModeSetuid    = 04000
+	unixModeSetgid    = 02000
+	unixModeSticky    = 01000
+)
+
+// ShellCommandError is returned when a command run on the device exits
+// with a non-zero status
+type ShellCommandError struct {
+	ExitCode int
+	Output   string
+}
+
+func (e *ShellCommandError) Error() string {
+	output := strings.TrimSpace(e.Output)
+	if output == "" {
+		return fmt.Sprintf("command failed with exit status %d", e.ExitCode)
+	}
+	return fmt.Sprintf("command failed with exit status %d: %s", e.ExitCode, output)
+}
+
+func (c *Client) runFsCommand(ctx context.Context, command string) ([]byte, error) {
+	var out bytes.Buffer
+	exitCode, err := c.RunShellCommand(ctx, command, &out)
+	if err != nil {
+		return nil, err
+	}
+	if exitCode != 0 {
+		return nil, &ShellCommandError{ExitCode: exitCode, Output: out.String()}
+	}
+	return out.Bytes(), nil
+}
+
+// ListDir returns the file information of the entries of a directory on
+// the connected device, or of the file itself if the path is not a directory
+func (c *Client) ListDir(ctx context.Context, path string) ([]FileInfo, error) {
+	q := ShellQuote(path)
+	command := fmt.Sprintf(
+		"if [ -d %[1]s ]; then "+
+			"find %[1]s -mindepth 1 -maxdepth 1 -exec stat -c '%[2]s' {} \\;; "+
+			"else stat -c '%[2]s' %[1]s; fi",
+		q, statFormat,
+	)
+	out, err := c.runFsCommand(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []FileInfo{}
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		fileInfo, err := parseStatLine(line)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, *fileInfo)
+	}
+	return files, s.Err()
+}
+
+// Remove removes a file, or a directory if recursive is set, on the
+// connected device
+func (c *Client) Remove(ctx context.Context, path string, recursive bool) error {
+	command := "rm -- " + ShellQuote(path)
+	if recursive {
+		command = "rm -r -- " + ShellQuote(path)
+	}
+	_, err := c.runFsCommand(ctx, command)
+	return err
+}
+
+// MakeDir creates a directory, and its parents if parents is set, on the
+// connected device
+func (c *Client) MakeDir(ctx context.Context, path string, parents bool) error {
+	command := "mkdir -- " + ShellQuote(path)
+	if parents {
+		command = "mkdir -p -- " + ShellQuote(path)
+	}
+	_, err := c.runFsCommand(ctx, command)
+	return err
+}
+
+func parseStatLine(line string) (*FileInfo, error) {
+	fields := strings.SplitN(line, " ", 6)
+	if len(fields) != 6 {
+		return nil, errors.Errorf("Unable to parse the file information: %q", line)
+	}
+	mode, err := strconv.ParseUint(fields[0], 16, 32)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to parse the file mode: %q", line)
+	}
+	uid, err := strconv.ParseUint(fields[1], 10, 32)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to parse the file owner: %q", line)
+	}
+	gid, err := strconv.ParseUint(fields[2], 10, 32)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to parse the file group: %q", line)
+	}
+	size, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to parse the file size: %q", line)
+	}
+	mtime, err := strconv.ParseInt(fields[4], 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to parse the file modification time: %q", line)
+	}
+
+	fileInfo := &FileInfo{}
+	fileMode := uint32(unixModeToFileMode(uint32(mode)))
+	uid32, gid32 := uint32(uid), uint32(gid)
+	modTime := time.Unix(mtime, 0)
+	fileInfo.Path = &fields[5]
+	fileInfo.Mode = &fileMode
+	fileInfo.UID = &uid32
+	fileInfo.GID = &gid32
+	fileInfo.Size = &size
+	fileInfo.ModTime = &modTime
+	return fileInfo, nil
+}
+
+func unixModeToFileMode(mode uint32) os.FileMode {
+	fileMode := os.FileMode(mode & 0777)
+	switch mode & unixModeTypeMask {
+	case unixModeSocket:
+		fileMode |= os.ModeSocket
+	case unixModeSymlink:
+		fileMode |= os.ModeSymlink
+	case unixModeBlock:
+		fileMode |= os.ModeDevice
+	case unixModeDir:
+		fileMode |= os.ModeDir
+	case unixModeChar:
+		fileMode |= os.ModeDevice | os.ModeCharDevice
+	case unixModeNamedPipe:
+		fileMode |= os.ModeNamedPipe
+	}
+	if mode&unixModeSetuid != 0 {
+		fileMode |= os.ModeSetuid
+	}
+	if mode&unixModeSetgid != 0 {
+		fileMode |= os.ModeSetgid
+	}
+	if mode&unixModeSticky != 0 {
+		fileMode |= os.ModeSticky
+	}
+	return fileMode
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package deviceconnect
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mendersoftware/go-lib-micro/ws"
+	wsshell "github.com/mendersoftware/go-lib-micro/ws/shell"
+	"github.com/pkg/errors"
+)
+
+const (
+	// deviceconnect session playback API path
+	sessionPlaybackPath = "/api/management/v1/deviceconnect/sessions/:sessionID/playback"
+
+	// the server doesn't sleep between the messages, as the delays are
+	// sent as delay messages
+	playbackSleepMs = "0"
+
+	// playback delay message, sent by the server between the recorded
+	// messages
+	messageTypeDelay   = "delay"
+	propertyDelayValue = "delay_value"
+)
+
+// SessionFrameType is the type of a frame of a session recording
+type SessionFrameType int
+
+const (
+	SessionFrameOutput SessionFrameType = iota
+	SessionFrameResize
+)
+
+// SessionFrame is a frame of a session recording
+type SessionFrame struct {
+	Type SessionFrameType
+	// Data is the terminal output of output frames
+	Data []byte
+	// Offset is the time elapsed since the start of the session
+	Offset time.Duration
+	// TerminalWidth and TerminalHeight are the terminal size of resize
+	// frames
+	TerminalWidth  int
+	TerminalHeight int
+}
+
+// GetSessionRecording downloads the recording of a terminal session
+func (c *Client) GetSessionRecording(sessionID string) ([]SessionFrame, error) {
+	path := strings.Replace(sessionPlaybackPath, ":sessionID", url.PathEscape(sessionID), 1)
+	err := c.dial(path+"?"+url.Values{"sleep_ms": []string{playbackSleepMs}}.Encode(), c.token)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to start the session playback")
+	}
+	defer c.Close()
+
+	frames := []SessionFrame{}
+	var offset time.Duration
+	for {
+		m, err := c.ReadMessage()
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			return frames, nil
+		} else if len(frames) > 0 && websocket.IsCloseError(err,
+			websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			// the server may drop the connection after the last frame
+			return frames, nil
+		} else if err != nil {
+			return nil, errors.Wrap(err, "Unable to read the session recording")
+		}
+		if m.Header.Proto == ws.ProtoTypeControl && m.Header.MsgType == ws.MessageTypeError {
+			return nil, errors.Errorf("session playback failed: %s", string(m.Body))
+		} else if m.Header.Proto != ws.ProtoTypeShell {
+			continue
+		}
+		switch m.Header.MsgType {
+		case messageTypeDelay:
+			if delay, ok := numberProperty(m.Header.Properties, propertyDelayValue); ok {
+				offset += time.Duration(delay) * time.Millisecond
+			}
+		case wsshell.MessageTypeShellCommand:
+			frames = append(frames, SessionFrame{
+				Type:   SessionFrameOutput,
+				Data:   m.Body,
+				Offset: offset,
+			})
+		case wsshell.MessageTypeSpawnShell, wsshell.MessageTypeResizeShell:
+			width, ok1 := numberProperty(m.Header.Properties, "terminal_width")
+			height, ok2 := numberProperty(m.Header.Properties, "terminal_height")
+			if ok1 && ok2 {
+				frames = append(frames, SessionFrame{
+					Type:           SessionFrameResize,
+					Offset:         offset,
+					TerminalWidth:  int(width),
+					TerminalHeight: int(height),
+				})
+			}
+		}
+	}
+}
+
+// numberProperty returns a numeric property of a message, which may be
+// decoded as any integer or floating point type
+func numberProperty(properties map[string]interface{}, name string) (float64, bool) {
+	switch v := properties[name].(type) {
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package deviceconnect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mendersoftware/go-lib-micro/ws"
+	wsshell "github.com/mendersoftware/go-lib-micro/ws/shell"
+	"github.com/vmihailenco/msgpack"
+)
+
+func TestGetSessionRecording(t *testing.T) {
+	t.Parallel()
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dropped := r.URL.Path == "/api/management/v1/deviceconnect/sessions/dropped/playback"
+		if r.URL.Path != "/api/management/v1/deviceconnect/sessions/session-1/playback" &&
+			!dropped {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err.Error())
+			return
+		}
+		defer conn.Close()
+
+		for _, m := range []ws.ProtoMsg{
+			{Header: ws.ProtoHdr{Proto: ws.ProtoTypeShell, MsgType: wsshell.MessageTypeShellCommand},
+				Body: []byte("$ ")},
+			{Header: ws.ProtoHdr{Proto: ws.ProtoTypeShell, MsgType: messageTypeDelay,
+				Properties: map[string]interface{}{propertyDelayValue: 1500}}},
+			{Header: ws.ProtoHdr{Proto: ws.ProtoTypeShell, MsgType: wsshell.MessageTypeResizeShell,
+				Properties: map[string]interface{}{
+					"terminal_width":  uint16(100),
+					"terminal_height": uint16(50),
+				}}},
+			{Header: ws.ProtoHdr{Proto: ws.ProtoTypeShell, MsgType: wsshell.MessageTypeShellCommand},
+				Body: []byte("ls\r\n")},
+		} {
+			data, _ := msgpack.Marshal(m)
+			if err := conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+				return
+			}
+		}
+		if dropped {
+			// close the connection without a close message
+			return
+		}
+		_ = conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "token", true)
+	frames, err := client.GetSessionRecording("session-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if len(frames) != 3 {
+		t.Fatalf("Expected 3 frames, got %d", len(frames))
+	}
+	if string(frames[0].Data) != "$ " || frames[0].Offset != 0 {
+		t.Errorf("Unexpected frame: %+v", frames[0])
+	}
+	if frames[1].Type != SessionFrameResize || frames[1].TerminalWidth != 100 ||
+		frames[1].Offset != 1500*time.Millisecond {
+		t.Errorf("Unexpected frame: %+v", frames[1])
+	}
+	if string(frames[2].Data) != "ls\r\n" || frames[2].Offset != 1500*time.Millisecond {
+		t.Errorf("Unexpected frame: %+v", frames[2])
+	}
+
+	// the frames received before the connection is dropped are kept
+	frames, err = client.GetSessionRecording("dropped")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if len(frames) != 3 {
+		t.Fatalf("Expected 3 frames, got %d", len(frames))
+	}
+
+	if _, err := client.GetSessionRecording("other"); err == nil {
+		t.Error("Expected an error for an unknown session")
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package deviceconnect
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mendersoftware/go-lib-micro/ws"
+	wsshell "github.com/mendersoftware/go-lib-micro/ws/shell"
+	"github.com/pkg/errors"
+)
+
+const (
+	// terminal size of non-interactive shell sessions
+	shellTermWidth  = 200
+	shellTermHeight = 40
+)
+
+// ShellQuote quotes a string to be used as a single shell word
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// sentinel delimits the output of a command run in a shell session; the
+// markers are printed with printf so that the echo of the command line
+// itself never matches them
+type sentinel struct {
+	id    string
+	begin []byte
+	end   []byte
+}
+
+func newSentinel() *sentinel {
+	id := "mender-cli-" + uuid.NewString()
+	return &sentinel{
+		id:    id,
+		begin: []byte(id + "-begin"),
+		end:   []byte(id + "-end:"),
+	}
+}
+
+// wrap returns the command line to send to the shell; the command runs
+// with stdin redirected so it can't consume the rest of the input
+func (s *sentinel) wrap(command string) string {
+	return fmt.Sprintf(
+		"stty -echo -onlcr 2>/dev/null; printf '%%s-%%s\\n' %s begin; { %s\n"+
+			"} </dev/null; printf '\\n%%s-%%s:%%d\\n' %s end $?\n",
+		s.id, command, s.id,
+	)
+}
+
+// sentinelWriter forwards the output between the begin and end markers to
+// the underlying writer, holding back what could be a partial end marker
+type sentinelWriter struct {
+	*sentinel
+	w        io.Writer
+	buf      []byte
+	started  bool
+	skipEOL  bool
+	done     bool
+	exitCode int
+}
+
+func (s *sentinelWriter) Write(data []byte) (int, error) {
+	if s.done {
+		return len(data), nil
+	}
+	s.buf = append(s.buf, data...)
+	if !s.started {
+		i := bytes.Index(s.buf, s.begin)
+		if i < 0 {
+			return len(data), nil
+		}
+		s.started = true
+		s.skipEOL = true
+		s.buf = s.buf[i+len(s.begin):]
+	}
+	// skip the end of line following the begin marker
+	for s.skipEOL && len(s.buf) > 0 {
+		c := s.buf[0]
+		if c == '\r' || c == '\n' {
+			s.buf = s.buf[1:]
+		}
+		s.skipEOL = c == '\r'
+	}
+	if s.skipEOL {
+		return len(data), nil
+	}
+	if i := bytes.Index(s.buf, s.end); i >= 0 {
+		status := s.buf[i+len(s.end):]
+		j := bytes.IndexAny(status, "\r\n")
+		if j < 0 {
+			// wait for the rest of the exit status
+			return len(data), nil
+		}
+		exitCode, err := strconv.Atoi(string(status[:j]))
+		if err != nil {
+			return 0, errors.Wrap(err, "Unable to parse the exit status")
+		}
+		s.exitCode = exitCode
+		s.done = true
+		// drop the end of line printed before the end marker
+		out := bytes.TrimSuffix(s.buf[:i], []byte("\n"))
+		out = bytes.TrimSuffix(out, []byte("\r"))
+		s.buf = nil
+		_, err = s.w.Write(out)
+		return len(data), err
+	}
+	if n := len(s.buf) - len(s.end) - 2; n > 0 {
+		if _, err := s.w.Write(s.buf[:n]); err != nil {
+			return 0, err
+		}
+		s.buf = s.buf[n:]
+	}
+	return len(data), nil
+}
+
+// RunShellCommand runs a command in a new shell session on the connected
+// device, writing its output to w and returning its exit status
+func (c *Client) RunShellCommand(ctx context.Context, command string, w io.Writer) (int, error) {
+	m := &ws.ProtoMsg{
+		Header: ws.ProtoHdr{
+			Proto:   ws.ProtoTypeShell,
+			MsgType: wsshell.MessageTypeSpawnShell,
+			Properties: map[string]interface{}{
+				"terminal_width":  shellTermWidth,
+				"terminal_height": shellTermHeight,
+			},
+		},
+	}
+	if err := c.WriteMessage(m); err != nil {
+		return -1, err
+	}
+
+	// interrupt the blocking read when the context is done
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = c.conn.SetReadDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	s := &sentinelWriter{sentinel: newSentinel(), w: w}
+	sessionID := ""
+	defer func() {
+		if sessionID != "" {
+			_ = c.WriteMessage(&ws.ProtoMsg{
+				Header: ws.ProtoHdr{
+					Proto:     ws.ProtoTypeShell,
+					MsgType:   wsshell.MessageTypeStopShell,
+					SessionID: sessionID,
+				},
+			})
+		}
+	}()
+
+	for !s.done {
+		m, err := c.ReadMessage()
+		if ctx.Err() != nil {
+			return -1, ctx.Err()
+		} else if err != nil {
+			return -1, err
+		}
+		if m.Header.Proto != ws.ProtoTypeShell {
+			continue
+		}
+		switch m.Header.MsgType {
+		case wsshell.MessageTypeSpawnShell:
+			status, ok := m.Header.Properties["status"].(int64)
+			if ok && status == int64(wsshell.ErrorMessage) {
+				return -1, errors.Errorf("Unable to start the shell: %s", string(m.Body))
+			}
+			sessionID = m.Header.SessionID
+			err = c.WriteMessage(&ws.ProtoMsg{
+				Header: ws.ProtoHdr{
+					Proto:     ws.ProtoTypeShell,
+					MsgType:   wsshell.MessageTypeShellCommand,
+					SessionID: sessionID,
+				},
+				Body: []byte(s.wrap(command)),
+			})
+		case wsshell.MessageTypeShellCommand:
+			_, err = s.Write(m.Body)
+		case wsshell.MessageTypePingShell:
+			err = c.WriteMessage(&ws.ProtoMsg{
+				Header: ws.ProtoHdr{
+					Proto:     ws.ProtoTypeShell,
+					MsgType:   wsshell.MessageTypePongShell,
+					SessionID: sessionID,
+				},
+			})
+		case wsshell.MessageTypeStopShell:
+			sessionID = ""
+			return -1, errors.New("the shell session was closed by the device")
+		}
+		if err != nil {
+			return -1, err
+		}
+	}
+	return s.exitCode, nil
+}
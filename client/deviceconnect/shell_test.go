@@ -0,0 +1,113 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package deviceconnect
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+)
+
+func TestSentinelWriter(t *testing.T) {
+	t.Parallel()
+	s := newSentinel()
+	testCases := map[string]struct {
+		chunks   []string
+		output   string
+		exitCode int
+		done     bool
+	}{
+		"ok": {
+			chunks: []string{
+				"$ " + s.wrap("ls"),
+				s.id + "-begin\nfoo\nbar\n\n" + s.id + "-end:0\n$ ",
+			},
+			output: "foo\nbar\n",
+			done:   true,
+		},
+		"ok, with carriage returns": {
+			chunks: []string{
+				s.id + "-begin\r\nfoo\r\n\r\n" + s.id + "-end:2\r\n",
+			},
+			output:   "foo\r\n",
+			exitCode: 2,
+			done:     true,
+		},
+		"ok, byte by byte": {
+			chunks: func() []string {
+				out := s.id + "-begin\nfoo\n" + s.id + "-end:127\n"
+				chunks := make([]string, len(out))
+				for i := range out {
+					chunks[i] = out[i : i+1]
+				}
+				return chunks
+			}(),
+			output:   "foo",
+			exitCode: 127,
+			done:     true,
+		},
+		"not done": {
+			chunks: []string{
+				s.id + "-begin\nfoo bar baz\n" + s.id + "-end",
+			},
+			output: "foo bar baz",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			w := &sentinelWriter{sentinel: s, w: &buf}
+			for _, chunk := range tc.chunks {
+				if _, err := w.Write([]byte(chunk)); err != nil {
+					t.Fatalf("Unexpected error: %s", err.Error())
+				}
+			}
+			if w.done != tc.done {
+				t.Errorf("Expected done: %t, got: %t", tc.done, w.done)
+			}
+			if tc.done && buf.String() != tc.output {
+				t.Errorf("Expected output: %q, got: %q", tc.output, buf.String())
+			}
+			if !tc.done && !bytes.HasPrefix([]byte(tc.output), buf.Bytes()) {
+				t.Errorf("Unexpected partial output: %q", buf.String())
+			}
+			if w.exitCode != tc.exitCode {
+				t.Errorf("Expected exit code: %d, got: %d", tc.exitCode, w.exitCode)
+			}
+		})
+	}
+}
+
+func TestSentinelWrap(t *testing.T) {
+	t.Parallel()
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	s := newSentinel()
+	var buf bytes.Buffer
+	w := &sentinelWriter{sentinel: s, w: &buf}
+	cmd := exec.Command(sh)
+	cmd.Stdin = bytes.NewBufferString(s.wrap("echo " + ShellQuote("it's") + "; (exit 3)"))
+	cmd.Stdout = w
+	_ = cmd.Run()
+	if buf.String() != "it's\n" {
+		t.Errorf("Unexpected output: %q", buf.String())
+	}
+	if !w.done || w.exitCode != 3 {
+		t.Errorf("Expected exit code 3, got: %d (done: %t)", w.exitCode, w.done)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mendersoftware/mender-cli/client"
+	"github.com/mendersoftware/mender-cli/log"
+)
+
+const (
+	groupDevicesURL = "/api/management/v1/inventory/groups/:name/devices"
+
+	groupDevicesPerPage = 500
+)
+
+type Client struct {
+	url             string
+	groupDevicesURL string
+	client          *http.Client
+}
+
+func NewClient(url string, skipVerify bool) *Client {
+	return &Client{
+		url:             url,
+		groupDevicesURL: client.JoinURL(url, groupDevicesURL),
+		client:          client.NewHttpClient(skipVerify),
+	}
+}
+
+// ListGroupDevices returns the IDs of all the devices in a group
+func (c *Client) ListGroupDevices(token, group string) ([]string, error) {
+	deviceIDs := []string{}
+	for page := 1; ; page++ {
+		req, err := http.NewRequest(http.MethodGet,
+			strings.ReplaceAll(c.groupDevicesURL, ":name", url.PathEscape(group)), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare request: %w", err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		q := url.Values{
+			"per_page": []string{strconv.Itoa(groupDevicesPerPage)},
+			"page":     []string{strconv.Itoa(page)},
+		}
+		req.URL.RawQuery = q.Encode()
+
+		reqDump, err := httputil.DumpRequest(req, false)
+		if err != nil {
+			return nil, err
+		}
+		log.Verbf("sending request: \n%s", string(reqDump))
+
+		rsp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if rsp.StatusCode != http.StatusOK {
+			rsp.Body.Close()
+			if rsp.StatusCode == http.StatusNotFound {
+				return nil, fmt.Errorf("group %q not found", group)
+			}
+			return nil, fmt.Errorf("GET %s request failed with status %d",
+				req.URL.RequestURI(), rsp.StatusCode)
+		}
+
+		var list []string
+		err = json.NewDecoder(rsp.Body).Decode(&list)
+		rsp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		deviceIDs = append(deviceIDs, list...)
+		if len(list) < groupDevicesPerPage {
+			return deviceIDs, nil
+		}
+	}
+}
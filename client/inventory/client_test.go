@@ -0,0 +1,62 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestListGroupDevices(t *testing.T) {
+	t.Parallel()
+	total := groupDevicesPerPage + 3
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/management/v1/inventory/groups/my%20group/devices" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		list := []string{}
+		for i := (page - 1) * perPage; i < page*perPage && i < total; i++ {
+			list = append(list, fmt.Sprintf("device-%d", i))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(list)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, true)
+	deviceIDs, err := client.ListGroupDevices("token", "my group")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if len(deviceIDs) != total {
+		t.Errorf("Expected %d devices, got %d", total, len(deviceIDs))
+	}
+	if deviceIDs[total-1] != fmt.Sprintf("device-%d", total-1) {
+		t.Errorf("Unexpected last device ID: %s", deviceIDs[total-1])
+	}
+
+	_, err = client.ListGroupDevices("token", "other")
+	if err == nil {
+		t.Error("Expected an error for an unknown group")
+	}
+}
@@ -0,0 +1,451 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/mendersoftware/mender-artifact/artifact"
+	"github.com/mendersoftware/mender-artifact/awriter"
+	"github.com/mendersoftware/mender-artifact/handlers"
+
+	"github.com/mendersoftware/mender-cli/log"
+)
+
+const (
+	argDeviceType   = "device-type"
+	argArtifactName = "artifact-name"
+	argOutputPath   = "output-path"
+	argProvides     = "provides"
+	argDepends      = "depends"
+	argSigningKey   = "key"
+	argUpload       = "upload"
+	argDestDir      = "dest-dir"
+	argUpdateType   = "type"
+
+	// update types of the update modules shipped with the Mender client
+	updateTypeSingleFile = "single-file"
+	updateTypeDirectory  = "directory"
+
+	artifactFormat  = "mender"
+	artifactVersion = 3
+)
+
+var artifactCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create mender artifacts.",
+	Long: "Create mender artifacts for the single-file and directory update modules,\n" +
+		"or for any update module, without the mender-artifact tool.\n\n" +
+		"By default, the artifacts provide the artifact name as the version of the\n" +
+		"software, in rootfs-image.UPDATE_TYPE.version. With --upload, the artifact\n" +
+		"is uploaded to the Mender server once created.",
+	ValidArgs: []string{updateTypeSingleFile, updateTypeDirectory, "module-image"},
+}
+
+var artifactCreateSingleFileCmd = &cobra.Command{
+	Use:   "single-file [flags] FILE",
+	Short: "Create an artifact installing a single file.",
+	Example: "  mender-cli artifacts create single-file -t raspberrypi4 -n config-1.0 \\\n" +
+		"    --dest-dir /etc/myapp config.json",
+	Args: cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewArtifactCreateCmd(c, args, updateTypeSingleFile)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+var artifactCreateDirectoryCmd = &cobra.Command{
+	Use:   "directory [flags] DIRECTORY",
+	Short: "Create an artifact replacing the content of a directory.",
+	Example: "  mender-cli artifacts create directory -t raspberrypi4 -n www-1.0 \\\n" +
+		"    --dest-dir /var/www ./www",
+	Args: cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewArtifactCreateCmd(c, args, updateTypeDirectory)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+var artifactCreateModuleImageCmd = &cobra.Command{
+	Use:   "module-image [flags] [FILE...]",
+	Short: "Create an artifact for an update module.",
+	Example: "  mender-cli artifacts create module-image -t raspberrypi4 -n app-1.0 \\\n" +
+		"    --type docker manifest.json",
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewArtifactCreateCmd(c, args, "")
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+func init() {
+	flags := artifactCreateCmd.PersistentFlags()
+	flags.StringSliceP(argDeviceType, "t", nil, "compatible device type (repeatable)")
+	flags.StringP(argArtifactName, "n", "", "artifact name")
+	flags.StringP(argOutputPath, "o", "",
+		"path of the artifact file, ARTIFACT_NAME.mender by default")
+	flags.StringArrayP(argProvides, "p", nil,
+		"update provides, in the form KEY:VALUE (repeatable)")
+	flags.StringArrayP(argDepends, "d", nil,
+		"update depends, in the form KEY:VALUE (repeatable)")
+	flags.StringP(argSigningKey, "", "", "sign the artifact with this private key (PEM)")
+	flags.BoolP(argUpload, "", false, "upload the artifact to the Mender server")
+	flags.StringP(argArtifactDescription, "", "", "artifact description, with --upload")
+	flags.BoolP(argWithoutProgress, "", false, "disable progress bar, with --upload")
+	flags.BoolP(argDirect, "", false, "upload directly to storage, with --upload")
+	flags.StringP(argVerifyKey, "", "",
+		"verify the artifact signature with this public key (PEM), with --upload")
+
+	artifactCreateSingleFileCmd.Flags().StringP(argDestDir, "", "",
+		"directory of the device where the file is installed")
+	artifactCreateDirectoryCmd.Flags().StringP(argDestDir, "", "",
+		"directory of the device whose content is replaced")
+	artifactCreateModuleImageCmd.Flags().StringP(argUpdateType, "T", "",
+		"update type, the name of the update module")
+
+	artifactCreateCmd.AddCommand(artifactCreateSingleFileCmd)
+	artifactCreateCmd.AddCommand(artifactCreateDirectoryCmd)
+	artifactCreateCmd.AddCommand(artifactCreateModuleImageCmd)
+}
+
+type ArtifactCreateCmd struct {
+	updateType  string
+	source      string
+	destDir     string
+	files       []string
+	name        string
+	deviceTypes []string
+	provides    artifact.TypeInfoProvides
+	depends     artifact.TypeInfoDepends
+	signingKey  []byte
+	outputPath  string
+	upload      *ArtifactUploadCmd
+}
+
+func NewArtifactCreateCmd(
+	cmd *cobra.Command,
+	args []string,
+	updateType string,
+) (*ArtifactCreateCmd, error) {
+	flags := cmd.Flags()
+
+	name, err := flags.GetString(argArtifactName)
+	if err != nil {
+		return nil, err
+	} else if name == "" {
+		return nil, errors.Errorf("the artifact name is required: specify it with --%s",
+			argArtifactName)
+	}
+
+	deviceTypes, err := flags.GetStringSlice(argDeviceType)
+	if err != nil {
+		return nil, err
+	} else if len(deviceTypes) == 0 {
+		return nil, errors.Errorf("at least one device type is required: specify it with --%s",
+			argDeviceType)
+	}
+
+	c := &ArtifactCreateCmd{
+		updateType:  updateType,
+		name:        name,
+		deviceTypes: deviceTypes,
+	}
+	switch updateType {
+	case updateTypeSingleFile, updateTypeDirectory:
+		c.source = args[0]
+		c.destDir, err = flags.GetString(argDestDir)
+		if err != nil {
+			return nil, err
+		} else if c.destDir == "" {
+			return nil, errors.Errorf("the destination directory is required: "+
+				"specify it with --%s", argDestDir)
+		}
+	default:
+		c.updateType, err = flags.GetString(argUpdateType)
+		if err != nil {
+			return nil, err
+		} else if c.updateType == "" {
+			return nil, errors.Errorf("the update type is required: specify it with --%s",
+				argUpdateType)
+		}
+		c.files = args
+	}
+
+	c.provides = artifact.TypeInfoProvides{
+		"rootfs-image." + c.updateType + ".version": name,
+	}
+	provides, err := flags.GetStringArray(argProvides)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range provides {
+		key, value, ok := strings.Cut(p, ":")
+		if !ok || key == "" {
+			return nil, errors.New("invalid provides, expected KEY:VALUE: " + p)
+		}
+		c.provides[key] = value
+	}
+
+	depends, err := flags.GetStringArray(argDepends)
+	if err != nil {
+		return nil, err
+	}
+	if len(depends) > 0 {
+		c.depends = artifact.TypeInfoDepends{}
+	}
+	for _, d := range depends {
+		key, value, ok := strings.Cut(d, ":")
+		if !ok || key == "" {
+			return nil, errors.New("invalid depends, expected KEY:VALUE: " + d)
+		}
+		c.depends[key] = value
+	}
+
+	signingKeyPath, err := flags.GetString(argSigningKey)
+	if err != nil {
+		return nil, err
+	}
+	if signingKeyPath != "" {
+		c.signingKey, err = os.ReadFile(signingKeyPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to read the signing key")
+		}
+	}
+
+	c.outputPath, err = flags.GetString(argOutputPath)
+	if err != nil {
+		return nil, err
+	} else if c.outputPath == "" {
+		c.outputPath = name + ".mender"
+	}
+
+	upload, err := flags.GetBool(argUpload)
+	if err != nil {
+		return nil, err
+	}
+	if upload {
+		c.upload, err = newArtifactFileUploadCmd(cmd, c.outputPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *ArtifactCreateCmd) Run() error {
+	files := c.files
+	if c.updateType == updateTypeSingleFile || c.updateType == updateTypeDirectory {
+		tmpDir, err := os.MkdirTemp("", "mender-cli-artifact")
+		if err != nil {
+			return errors.Wrap(err, "Unable to create a temporary directory")
+		}
+		defer os.RemoveAll(tmpDir)
+		if c.updateType == updateTypeSingleFile {
+			files, err = singleFileUpdateFiles(tmpDir, c.source, c.destDir)
+		} else {
+			files, err = directoryUpdateFiles(tmpDir, c.source, c.destDir)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := c.writeArtifact(files); err != nil {
+		return err
+	}
+	log.Infof("artifact created: %s\n", c.outputPath)
+
+	if c.upload != nil {
+		return c.upload.Run()
+	}
+	return nil
+}
+
+// writeArtifact writes a module-image artifact with the given payload files
+func (c *ArtifactCreateCmd) writeArtifact(files []string) error {
+	update := handlers.NewModuleImage(c.updateType)
+	dataFiles := make([]*handlers.DataFile, 0, len(files))
+	for _, file := range files {
+		dataFiles = append(dataFiles, &handlers.DataFile{Name: file})
+	}
+	if err := update.SetUpdateFiles(dataFiles); err != nil {
+		return errors.Wrap(err, "Unable to add the payload files")
+	}
+
+	var signer artifact.Signer
+	if c.signingKey != nil {
+		var err error
+		signer, err = artifact.NewPKISigner(c.signingKey)
+		if err != nil {
+			return errors.Wrap(err, "Invalid signing key")
+		}
+	}
+
+	f, err := os.OpenFile(c.outputPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return errors.Wrap(err, "Unable to create the artifact file")
+	}
+	defer f.Close()
+
+	var writer *awriter.Writer
+	if signer != nil {
+		writer = awriter.NewWriterSigned(f, artifact.NewCompressorGzip(), signer)
+	} else {
+		writer = awriter.NewWriter(f, artifact.NewCompressorGzip())
+	}
+
+	err = writer.WriteArtifact(&awriter.WriteArtifactArgs{
+		Format:  artifactFormat,
+		Version: artifactVersion,
+		Devices: c.deviceTypes,
+		Name:    c.name,
+		Updates: &awriter.Updates{Updates: []handlers.Composer{update}},
+		Scripts: &artifact.Scripts{},
+		Provides: &artifact.ArtifactProvides{
+			ArtifactName: c.name,
+		},
+		Depends: &artifact.ArtifactDepends{
+			CompatibleDevices: c.deviceTypes,
+		},
+		TypeInfoV3: &artifact.TypeInfoV3{
+			Type:                   &c.updateType,
+			ArtifactProvides:       c.provides,
+			ArtifactDepends:        c.depends,
+			ClearsArtifactProvides: []string{"rootfs-image." + c.updateType + ".*"},
+		},
+	})
+	if err != nil {
+		f.Close()
+		_ = os.Remove(c.outputPath)
+		return errors.Wrap(err, "Unable to write the artifact")
+	}
+	return f.Close()
+}
+
+// singleFileUpdateFiles prepares the payload files of the single-file
+// update module in dir, like the single-file-artifact-gen script
+func singleFileUpdateFiles(dir, source, destDir string) ([]string, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read the file")
+	} else if !info.Mode().IsRegular() {
+		return nil, errors.Errorf("not a regular file: %s", source)
+	}
+	filename := filepath.Base(source)
+	metadata := map[string]string{
+		"dest_dir":    destDir,
+		"filename":    filename,
+		"permissions": fmt.Sprintf("%o", info.Mode().Perm()),
+	}
+	files := []string{}
+	for _, name := range []string{"dest_dir", "filename", "permissions"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(metadata[name]), 0644); err != nil {
+			return nil, errors.Wrap(err, "Unable to write the update metadata")
+		}
+		files = append(files, path)
+	}
+	return append(files, source), nil
+}
+
+// directoryUpdateFiles prepares the payload files of the directory update
+// module in dir, like the directory-artifact-gen script
+func directoryUpdateFiles(dir, source, destDir string) ([]string, error) {
+	destDirPath := filepath.Join(dir, "dest_dir")
+	if err := os.WriteFile(destDirPath, []byte(destDir), 0644); err != nil {
+		return nil, errors.Wrap(err, "Unable to write the update metadata")
+	}
+	tarPath := filepath.Join(dir, "update.tar")
+	f, err := os.Create(tarPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to create the update archive")
+	}
+	defer f.Close()
+	if err := writeDirectoryTar(f, source); err != nil {
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, errors.Wrap(err, "Unable to write the update archive")
+	}
+	return []string{destDirPath, tarPath}, nil
+}
+
+// writeDirectoryTar writes the content of a directory as a tar archive,
+// with the paths relative to the directory
+func writeDirectoryTar(w io.Writer, dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return errors.Wrap(err, "Unable to read the directory")
+	} else if !info.IsDir() {
+		return errors.Errorf("not a directory: %s", dir)
+	}
+
+	tw := tar.NewWriter(w)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil || name == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "Unable to archive the directory")
+	}
+	return tw.Close()
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestSingleFileUpdateFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	source := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(source, []byte("{}"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := singleFileUpdateFiles(dir, source, "/etc/myapp")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if len(files) != 4 || files[3] != source {
+		t.Fatalf("Unexpected files: %v", files)
+	}
+	for i, expected := range []string{"/etc/myapp", "config.json", "640"} {
+		data, err := os.ReadFile(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Errorf("Unexpected content of %s: %q", filepath.Base(files[i]), data)
+		}
+	}
+}
+
+func TestWriteDirectoryTar(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "css"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "css", "style.css"), []byte("p {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeDirectoryTar(&buf, dir); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	contents := map[string]string{}
+	tr := tar.NewReader(&buf)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		data, _ := io.ReadAll(tr)
+		contents[header.Name] = string(data)
+	}
+	expected := map[string]string{
+		"css/":          "",
+		"css/style.css": "p {}",
+		"index.html":    "<html>",
+	}
+	if !reflect.DeepEqual(contents, expected) {
+		t.Errorf("Unexpected archive contents: %v", contents)
+	}
+}
+
+// TestArtifactCreateUpload parses the flags of the artifacts create command,
+// so it doesn't run in parallel with the other tests
+func TestArtifactCreateUpload(t *testing.T) {
+	viper.Set(argRootServer, "https://mender.example.com")
+	t.Cleanup(func() {
+		viper.Set(argRootServer, "")
+	})
+	dir := t.TempDir()
+	source := filepath.Join(dir, "config.json")
+	outputPath := filepath.Join(dir, "release-1.mender")
+
+	cmd := artifactCreateSingleFileCmd
+	err := cmd.ParseFlags([]string{
+		"--" + argRootTokenValue, "token",
+		"--" + argArtifactName, "release-1",
+		"--" + argDeviceType, "raspberrypi4",
+		"--" + argDestDir, "/etc/myapp",
+		"--" + argOutputPath, outputPath,
+		"--" + argUpload,
+		"--" + argArtifactDescription, "my app configuration",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	c, err := NewArtifactCreateCmd(cmd, []string{source}, updateTypeSingleFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if c.upload == nil {
+		t.Fatal("Expected the artifact to be uploaded")
+	}
+	if c.upload.artifactPath != outputPath || c.upload.dir != "" {
+		t.Errorf("Unexpected upload of %q, directory %q", c.upload.artifactPath, c.upload.dir)
+	}
+	if c.upload.token != "token" || c.upload.description != "my app configuration" {
+		t.Errorf("Unexpected upload token %q and description %q",
+			c.upload.token, c.upload.description)
+	}
+}
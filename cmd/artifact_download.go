@@ -42,6 +42,8 @@ func init() {
 		"destination path to download to")
 	artifactDownloadCmd.Flags().BoolP(argWithoutProgress, "", false,
 		"disable progress bar")
+	artifactDownloadCmd.Flags().StringP(argVerifyKey, "", "",
+		"verify the artifact signature with this public key (PEM) before saving it")
 }
 
 type ArtifactDownloadCmd struct {
@@ -51,6 +53,7 @@ type ArtifactDownloadCmd struct {
 	artifactID      string
 	token           string
 	withoutProgress bool
+	verifyKey       []byte
 }
 
 func NewArtifactDownloadCmd(cmd *cobra.Command, args []string) (*ArtifactDownloadCmd, error) {
@@ -79,6 +82,11 @@ func NewArtifactDownloadCmd(cmd *cobra.Command, args []string) (*ArtifactDownloa
 		artifactID = args[0]
 	}
 
+	verifyKey, err := getArtifactVerifyKey(cmd)
+	if err != nil {
+		return nil, err
+	}
+
 	token, err := getAuthToken(cmd)
 	if err != nil {
 		return nil, err
@@ -91,16 +99,22 @@ func NewArtifactDownloadCmd(cmd *cobra.Command, args []string) (*ArtifactDownloa
 		token:           token,
 		skipVerify:      skipVerify,
 		withoutProgress: withoutProgress,
+		verifyKey:       verifyKey,
 	}, nil
 }
 
 func (c *ArtifactDownloadCmd) Run() error {
 	client := deployments.NewClient(c.server, c.skipVerify)
-	err := client.DownloadArtifact(c.destinationPath, c.artifactID, c.token, c.withoutProgress)
+	_, err := client.DownloadArtifact(
+		c.destinationPath, c.artifactID, c.token, c.withoutProgress, c.verifyKey)
 	if err != nil {
 		return err
 	}
 
+	if c.verifyKey != nil {
+		log.Info("artifact signature verified")
+	}
+
 	log.Info("download successful")
 
 	return nil
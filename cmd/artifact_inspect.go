@@ -0,0 +1,223 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/mendersoftware/mender-artifact/areader"
+	"github.com/mendersoftware/mender-artifact/artifact"
+)
+
+var artifactInspectCmd = &cobra.Command{
+	Use:   "inspect [flags] FILE",
+	Short: "Show the metadata of a local artifact file.",
+	Long: "Show the name, format, compatible device types, provides and depends,\n" +
+		"update types and payload files of a local artifact file, and whether it\n" +
+		"is signed, without contacting the Mender server.",
+	Args: cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewArtifactInspectCmd(c, args)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+func init() {
+	artifactInspectCmd.Flags().BoolP(
+		argRawMode,
+		"r",
+		false,
+		"artifact inspect raw mode (json)")
+}
+
+type ArtifactInspectCmd struct {
+	path    string
+	rawMode bool
+}
+
+// artifactInspection holds the metadata of an artifact file
+type artifactInspection struct {
+	Name                  string                     `json:"name"`
+	Format                string                     `json:"format"`
+	Version               int                        `json:"version"`
+	Signed                bool                       `json:"signed"`
+	DeviceTypesCompatible []string                   `json:"device_types_compatible"`
+	ArtifactProvides      *artifact.ArtifactProvides `json:"artifact_provides,omitempty"`
+	ArtifactDepends       *artifact.ArtifactDepends  `json:"artifact_depends,omitempty"`
+	Updates               []artifactInspectionUpdate `json:"updates"`
+}
+
+type artifactInspectionUpdate struct {
+	Type     string                    `json:"type"`
+	Provides artifact.TypeInfoProvides `json:"provides,omitempty"`
+	Depends  artifact.TypeInfoDepends  `json:"depends,omitempty"`
+	Files    []artifactInspectionFile  `json:"files"`
+}
+
+type artifactInspectionFile struct {
+	Name     string    `json:"name"`
+	Checksum string    `json:"checksum"`
+	Size     int64     `json:"size"`
+	Date     time.Time `json:"date"`
+}
+
+func NewArtifactInspectCmd(cmd *cobra.Command, args []string) (*ArtifactInspectCmd, error) {
+	rawMode, err := cmd.Flags().GetBool(argRawMode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ArtifactInspectCmd{
+		path:    args[0],
+		rawMode: rawMode,
+	}, nil
+}
+
+func (c *ArtifactInspectCmd) Run() error {
+	inspection, err := inspectArtifactFile(c.path)
+	if err != nil {
+		return err
+	}
+	if c.rawMode {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		return e.Encode(inspection)
+	}
+	printArtifactInspection(os.Stdout, inspection)
+	return nil
+}
+
+func inspectArtifactFile(path string) (*artifactInspection, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to open the artifact")
+	}
+	defer f.Close()
+	return inspectArtifact(f)
+}
+
+// inspectArtifact reads the metadata of an artifact; the signature, if
+// any, is not verified
+func inspectArtifact(r io.Reader) (*artifactInspection, error) {
+	ar := areader.NewReader(r)
+	if err := ar.ReadArtifact(); err != nil {
+		return nil, errors.Wrap(err, "Unable to read the artifact")
+	}
+
+	info := ar.GetInfo()
+	inspection := &artifactInspection{
+		Name:                  ar.GetArtifactName(),
+		Format:                info.Format,
+		Version:               info.Version,
+		Signed:                ar.IsSigned,
+		DeviceTypesCompatible: ar.GetCompatibleDevices(),
+		ArtifactProvides:      ar.GetArtifactProvides(),
+		ArtifactDepends:       ar.GetArtifactDepends(),
+	}
+
+	handlers := ar.GetHandlers()
+	for _, i := range slices.Sorted(maps.Keys(handlers)) {
+		handler := handlers[i]
+		var update artifactInspectionUpdate
+		if updateType := handler.GetUpdateType(); updateType != nil {
+			update.Type = *updateType
+		}
+		provides, err := handler.GetUpdateProvides()
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to read the update provides")
+		}
+		update.Provides = provides
+		depends, err := handler.GetUpdateDepends()
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to read the update depends")
+		}
+		update.Depends = depends
+		for _, f := range handler.GetUpdateAllFiles() {
+			update.Files = append(update.Files, artifactInspectionFile{
+				Name:     f.Name,
+				Checksum: string(f.Checksum),
+				Size:     f.Size,
+				Date:     f.Date,
+			})
+		}
+		inspection.Updates = append(inspection.Updates, update)
+	}
+	return inspection, nil
+}
+
+func printArtifactInspection(w io.Writer, a *artifactInspection) {
+	fmt.Fprintf(w, "Name: %s\n", a.Name)
+	fmt.Fprintf(w, "Artifact format: %s\n", a.Format)
+	fmt.Fprintf(w, "Format version: %d\n", a.Version)
+	fmt.Fprintf(w, "Signed: %t\n", a.Signed)
+	fmt.Fprintln(w, "Compatible types:")
+	for _, v := range a.DeviceTypesCompatible {
+		fmt.Fprintf(w, "  %s\n", v)
+	}
+	if p := a.ArtifactProvides; p != nil {
+		fmt.Fprintln(w, "Artifact provides:")
+		fmt.Fprintf(w, "  artifact_name: %s\n", p.ArtifactName)
+		if p.ArtifactGroup != "" {
+			fmt.Fprintf(w, "  artifact_group: %s\n", p.ArtifactGroup)
+		}
+	}
+	if d := a.ArtifactDepends; d != nil {
+		fmt.Fprintln(w, "Artifact depends:")
+		printInspectionList(w, "artifact_name", d.ArtifactName)
+		printInspectionList(w, "device_type", d.CompatibleDevices)
+		printInspectionList(w, "artifact_group", d.ArtifactGroup)
+	}
+	fmt.Fprintln(w, "Updates:")
+	for _, u := range a.Updates {
+		fmt.Fprintf(w, "  Type: %s\n", u.Type)
+		if len(u.Provides) > 0 {
+			fmt.Fprintln(w, "  Provides:")
+			for _, k := range slices.Sorted(maps.Keys(u.Provides)) {
+				fmt.Fprintf(w, "\t%s: %s\n", k, u.Provides[k])
+			}
+		}
+		if len(u.Depends) > 0 {
+			fmt.Fprintln(w, "  Depends:")
+			for _, k := range slices.Sorted(maps.Keys(u.Depends)) {
+				fmt.Fprintf(w, "\t%s: %v\n", k, u.Depends[k])
+			}
+		}
+		fmt.Fprintln(w, "  Files:")
+		for i, f := range u.Files {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "\tName: %s\n", f.Name)
+			fmt.Fprintf(w, "\tChecksum: %s\n", f.Checksum)
+			fmt.Fprintf(w, "\tSize: %d\n", f.Size)
+			fmt.Fprintf(w, "\tDate: %s\n", f.Date)
+		}
+	}
+}
+
+func printInspectionList(w io.Writer, name string, values []string) {
+	if len(values) > 0 {
+		fmt.Fprintf(w, "  %s: %v\n", name, values)
+	}
+}
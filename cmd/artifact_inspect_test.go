@@ -0,0 +1,77 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mendersoftware/mender-artifact/artifact"
+)
+
+func TestPrintArtifactInspection(t *testing.T) {
+	t.Parallel()
+	inspection := &artifactInspection{
+		Name:                  "release-1",
+		Format:                "mender",
+		Version:               3,
+		Signed:                true,
+		DeviceTypesCompatible: []string{"raspberrypi4"},
+		ArtifactProvides:      &artifact.ArtifactProvides{ArtifactName: "release-1"},
+		ArtifactDepends: &artifact.ArtifactDepends{
+			CompatibleDevices: []string{"raspberrypi4"},
+		},
+		Updates: []artifactInspectionUpdate{{
+			Type: "rootfs-image",
+			Provides: artifact.TypeInfoProvides{
+				"rootfs-image.version":  "release-1",
+				"rootfs-image.checksum": "abcd",
+			},
+			Files: []artifactInspectionFile{{
+				Name:     "rootfs.ext4",
+				Checksum: "abcd",
+				Size:     1024,
+				Date:     time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			}},
+		}},
+	}
+	var buf bytes.Buffer
+	printArtifactInspection(&buf, inspection)
+	expected := `Name: release-1
+Artifact format: mender
+Format version: 3
+Signed: true
+Compatible types:
+  raspberrypi4
+Artifact provides:
+  artifact_name: release-1
+Artifact depends:
+  device_type: [raspberrypi4]
+Updates:
+  Type: rootfs-image
+  Provides:
+	rootfs-image.checksum: abcd
+	rootfs-image.version: release-1
+  Files:
+	Name: rootfs.ext4
+	Checksum: abcd
+	Size: 1024
+	Date: 2025-01-02 03:04:05 +0000 UTC
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s", buf.String())
+	}
+}
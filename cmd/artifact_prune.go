@@ -0,0 +1,270 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mendersoftware/mender-cli/client/deployments"
+	"github.com/mendersoftware/mender-cli/log"
+)
+
+const (
+	argOlderThan           = "older-than"
+	argArtifactNamePattern = "name"
+	argKeepLast            = "keep-last"
+	argApply               = "apply"
+)
+
+var artifactPruneCmd = &cobra.Command{
+	Use:   "prune [flags]",
+	Short: "Delete the old artifacts from the Mender server.",
+	Long: "Delete the artifacts selected by age, name pattern or device type,\n" +
+		"keeping the most recent ones of each device type with --keep-last.\n" +
+		"The criteria are combined: an artifact is deleted if it matches all\n" +
+		"of them. The artifacts used by active deployments are never deleted.\n\n" +
+		"By default, the artifacts which would be deleted are only listed;\n" +
+		"they are deleted with --apply.",
+	Example: "  mender-cli artifacts prune --older-than 2160h --keep-last 3\n" +
+		"  mender-cli artifacts prune --name 'nightly-*' -t raspberrypi4 --apply",
+	Args: cobra.NoArgs,
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewArtifactPruneCmd(c, args)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+func init() {
+	artifactPruneCmd.Flags().DurationP(argOlderThan, "", 0,
+		"select the artifacts last modified longer ago than this, e.g. 720h")
+	artifactPruneCmd.Flags().StringP(argArtifactNamePattern, "", "",
+		"select the artifacts whose name matches this shell pattern")
+	artifactPruneCmd.Flags().StringP(argDeviceType, "t", "",
+		"select the artifacts compatible with this device type")
+	artifactPruneCmd.Flags().IntP(argKeepLast, "", 0,
+		"keep the N most recent of the selected artifacts of each device type")
+	artifactPruneCmd.Flags().BoolP(argApply, "", false,
+		"delete the selected artifacts instead of only listing them")
+}
+
+// pruneCriteria selects the artifacts to delete
+type pruneCriteria struct {
+	olderThan   time.Duration
+	namePattern string
+	deviceType  string
+	keepLast    int
+}
+
+type ArtifactPruneCmd struct {
+	server     string
+	skipVerify bool
+	token      string
+	criteria   pruneCriteria
+	apply      bool
+	output     io.Writer
+}
+
+func NewArtifactPruneCmd(cmd *cobra.Command, args []string) (*ArtifactPruneCmd, error) {
+	server := viper.GetString(argRootServer)
+	if server == "" {
+		return nil, errors.New("No server")
+	}
+
+	flags := cmd.Flags()
+
+	skipVerify, err := flags.GetBool(argRootSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	var criteria pruneCriteria
+	if criteria.olderThan, err = flags.GetDuration(argOlderThan); err != nil {
+		return nil, err
+	}
+	if criteria.namePattern, err = flags.GetString(argArtifactNamePattern); err != nil {
+		return nil, err
+	}
+	if _, err := path.Match(criteria.namePattern, ""); err != nil {
+		return nil, errors.Wrap(err, "Invalid name pattern")
+	}
+	if criteria.deviceType, err = flags.GetString(argDeviceType); err != nil {
+		return nil, err
+	}
+	if criteria.keepLast, err = flags.GetInt(argKeepLast); err != nil {
+		return nil, err
+	}
+	if criteria.olderThan < 0 || criteria.keepLast < 0 {
+		return nil, errors.Errorf("%s and %s arguments can't be negative",
+			argOlderThan, argKeepLast)
+	}
+	if criteria == (pruneCriteria{}) {
+		return nil, errors.Errorf("No artifacts selected: specify at least one of --%s, "+
+			"--%s, --%s or --%s", argOlderThan, argArtifactNamePattern, argDeviceType,
+			argKeepLast)
+	}
+
+	apply, err := flags.GetBool(argApply)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := getAuthToken(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ArtifactPruneCmd{
+		server:     server,
+		skipVerify: skipVerify,
+		token:      token,
+		criteria:   criteria,
+		apply:      apply,
+		output:     os.Stdout,
+	}, nil
+}
+
+func (c *ArtifactPruneCmd) Run() error {
+	client := deployments.NewClient(c.server, c.skipVerify)
+	artifacts, err := client.ListAllArtifacts(c.token)
+	if err != nil {
+		return errors.Wrap(err, "unable to list the artifacts")
+	}
+	active, err := client.ListActiveDeployments(c.token)
+	if err != nil {
+		return errors.Wrap(err, "unable to list the active deployments")
+	}
+
+	prune, inUse := selectArtifactsToPrune(artifacts, active, c.criteria, time.Now())
+	for _, a := range inUse {
+		log.Infof("keeping %s (%s): used by an active deployment\n", a.ID, a.Name)
+	}
+	if len(prune) == 0 {
+		log.Info("no artifacts to delete")
+		return nil
+	}
+	if err := printPrunePlan(c.output, prune); err != nil {
+		return err
+	}
+	if !c.apply {
+		log.Infof("%d artifacts would be deleted, run with --%s to delete them\n",
+			len(prune), argApply)
+		return nil
+	}
+
+	failed := 0
+	for _, a := range prune {
+		if err := client.DeleteArtifact(a.ID, c.token); err != nil {
+			log.Errf("failed to delete %s (%s): %s\n", a.ID, a.Name, err.Error())
+			failed++
+			continue
+		}
+		log.Verbf("deleted %s (%s)", a.ID, a.Name)
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d artifacts could not be deleted", failed, len(prune))
+	}
+	log.Infof("%d artifacts deleted\n", len(prune))
+	return nil
+}
+
+// selectArtifactsToPrune returns the artifacts matching the criteria, the
+// oldest first, and separately the matching ones used by active
+// deployments. As the deployments select the artifact of each device by
+// name, all the artifacts with the name of an active deployment are used.
+func selectArtifactsToPrune(
+	artifacts []deployments.ArtifactData,
+	active []deployments.Deployment,
+	criteria pruneCriteria,
+	now time.Time,
+) (prune, inUse []deployments.ArtifactData) {
+	selected := []deployments.ArtifactData{}
+	for _, a := range artifacts {
+		if criteria.namePattern != "" {
+			if ok, _ := path.Match(criteria.namePattern, a.Name); !ok {
+				continue
+			}
+		}
+		if criteria.deviceType != "" &&
+			!slices.Contains(a.DeviceTypesCompatible, criteria.deviceType) {
+			continue
+		}
+		selected = append(selected, a)
+	}
+	// the most recent first
+	slices.SortStableFunc(selected, func(a, b deployments.ArtifactData) int {
+		return b.Modified.Compare(a.Modified)
+	})
+
+	kept := map[string]bool{}
+	if criteria.keepLast > 0 {
+		count := map[string]int{}
+		for _, a := range selected {
+			for _, deviceType := range a.DeviceTypesCompatible {
+				if criteria.deviceType != "" && deviceType != criteria.deviceType {
+					continue
+				}
+				if count[deviceType] < criteria.keepLast {
+					kept[a.ID] = true
+				}
+				count[deviceType]++
+			}
+		}
+	}
+
+	usedIDs := map[string]bool{}
+	usedNames := map[string]bool{}
+	for _, d := range active {
+		usedNames[d.ArtifactName] = true
+		for _, id := range d.Artifacts {
+			usedIDs[id] = true
+		}
+	}
+
+	for i := len(selected) - 1; i >= 0; i-- {
+		a := selected[i]
+		if kept[a.ID] {
+			continue
+		} else if criteria.olderThan > 0 && now.Sub(a.Modified) < criteria.olderThan {
+			continue
+		} else if usedIDs[a.ID] || usedNames[a.Name] {
+			inUse = append(inUse, a)
+			continue
+		}
+		prune = append(prune, a)
+	}
+	return prune, inUse
+}
+
+func printPrunePlan(out io.Writer, artifacts []deployments.ArtifactData) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tDEVICE TYPES\tMODIFIED\tSIZE")
+	for _, a := range artifacts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", a.ID, a.Name,
+			strings.Join(a.DeviceTypesCompatible, ","),
+			a.Modified.Local().Format(time.RFC3339), a.Size)
+	}
+	return w.Flush()
+}
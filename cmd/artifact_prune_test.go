@@ -0,0 +1,104 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mendersoftware/mender-cli/client/deployments"
+)
+
+func TestSelectArtifactsToPrune(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	artifact := func(
+		id, name string,
+		age time.Duration,
+		deviceTypes ...string,
+	) deployments.ArtifactData {
+		return deployments.ArtifactData{
+			ID:                    id,
+			Name:                  name,
+			DeviceTypesCompatible: deviceTypes,
+			Modified:              now.Add(-age),
+		}
+	}
+	artifacts := []deployments.ArtifactData{
+		artifact("1", "release-1", 50*day, "rpi4"),
+		artifact("2", "release-1", 49*day, "rpi5"),
+		artifact("3", "release-2", 40*day, "rpi4", "rpi5"),
+		artifact("4", "nightly-1", 20*day, "rpi4"),
+		artifact("5", "nightly-2", 10*day, "rpi4"),
+		artifact("6", "nightly-3", 1*day, "rpi4"),
+	}
+	active := []deployments.Deployment{{
+		ArtifactName: "release-2",
+		Artifacts:    []string{"3"},
+	}}
+
+	testCases := map[string]struct {
+		criteria pruneCriteria
+		prune    []string
+		inUse    []string
+	}{
+		"older than": {
+			criteria: pruneCriteria{olderThan: 30 * day},
+			prune:    []string{"1", "2"},
+			inUse:    []string{"3"},
+		},
+		"name pattern": {
+			criteria: pruneCriteria{namePattern: "nightly-*"},
+			prune:    []string{"4", "5", "6"},
+		},
+		"device type": {
+			criteria: pruneCriteria{deviceType: "rpi5"},
+			prune:    []string{"2"},
+			inUse:    []string{"3"},
+		},
+		"keep last": {
+			criteria: pruneCriteria{keepLast: 2},
+			prune:    []string{"1", "4"},
+		},
+		"keep last of a device type": {
+			criteria: pruneCriteria{deviceType: "rpi4", keepLast: 1},
+			prune:    []string{"1", "4", "5"},
+			inUse:    []string{"3"},
+		},
+		"combined": {
+			criteria: pruneCriteria{namePattern: "nightly-*", olderThan: 5 * day, keepLast: 1},
+			prune:    []string{"4", "5"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prune, inUse := selectArtifactsToPrune(artifacts, active, tc.criteria, now)
+			ids := func(artifacts []deployments.ArtifactData) []string {
+				var ids []string
+				for _, a := range artifacts {
+					ids = append(ids, a.ID)
+				}
+				return ids
+			}
+			if !reflect.DeepEqual(ids(prune), tc.prune) {
+				t.Errorf("Unexpected artifacts to prune: %v, expected %v", ids(prune), tc.prune)
+			}
+			if !reflect.DeepEqual(ids(inUse), tc.inUse) {
+				t.Errorf("Unexpected artifacts in use: %v, expected %v", ids(inUse), tc.inUse)
+			}
+		})
+	}
+}
@@ -14,11 +14,22 @@
 package cmd
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"text/tabwriter"
+
 	"github.com/pkg/errors"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/mendersoftware/mender-artifact/areader"
+
 	"github.com/mendersoftware/mender-cli/client/deployments"
 	"github.com/mendersoftware/mender-cli/log"
 )
@@ -26,12 +37,31 @@ import (
 const (
 	argArtifactDescription = "description"
 	argDirect              = "direct"
+	argUploadDir           = "dir"
+
+	uploadDefaultParallel = 4
+
+	// results of the uploads of a directory
+	uploadStatusUploaded = "uploaded"
+	uploadStatusSkipped  = "skipped"
+	uploadStatusFailed   = "failed"
 )
 
 var artifactUploadCmd = &cobra.Command{
-	Use:   "upload [flags] ARTIFACT",
+	Use:   "upload [flags] {ARTIFACT | --dir DIRECTORY}",
 	Short: "Upload mender artifact to the Mender server.",
-	Args:  cobra.ExactArgs(1),
+	Long: "Upload a mender artifact to the Mender server.\n\n" +
+		"With --dir, all the .mender files of a directory are uploaded\n" +
+		"concurrently, without progress bars. The artifacts whose name and\n" +
+		"device types already exist on the server are skipped, and a summary\n" +
+		"of the uploads is printed at the end.\n\n" +
+		"With --direct, the artifact is uploaded directly to the storage.\n" +
+		"An interrupted upload is resumed by running the same command\n" +
+		"again, which only works with the Azure block blob storage; with\n" +
+		"other storages, the artifact is uploaded again in a single request.",
+	Example: "  mender-cli artifacts upload release-1.0.mender\n" +
+		"  mender-cli artifacts upload --dir build/artifacts --parallel 8",
+	Args: cobra.MaximumNArgs(1),
 	Run: func(c *cobra.Command, args []string) {
 		cmd, err := NewArtifactUploadCmd(c, args)
 		CheckErr(err)
@@ -42,7 +72,14 @@ var artifactUploadCmd = &cobra.Command{
 func init() {
 	artifactUploadCmd.Flags().StringP(argArtifactDescription, "", "", "artifact description")
 	artifactUploadCmd.Flags().BoolP(argWithoutProgress, "", false, "disable progress bar")
-	artifactUploadCmd.Flags().BoolP(argDirect, "", false, "upload directly to storage")
+	artifactUploadCmd.Flags().BoolP(argDirect, "", false,
+		"upload directly to storage, resuming the interrupted uploads on Azure block blobs")
+	artifactUploadCmd.Flags().StringP(argVerifyKey, "", "",
+		"verify the artifact signature with this public key (PEM) before uploading")
+	artifactUploadCmd.Flags().StringP(argUploadDir, "", "",
+		"upload all the artifacts of this directory")
+	artifactUploadCmd.Flags().IntP(argParallel, "p", uploadDefaultParallel,
+		"maximum number of artifacts to upload concurrently with --dir")
 }
 
 type ArtifactUploadCmd struct {
@@ -53,9 +90,49 @@ type ArtifactUploadCmd struct {
 	token           string
 	withoutProgress bool
 	direct          bool
+	verifyKey       []byte
+	dir             string
+	parallel        int
+	output          io.Writer
 }
 
 func NewArtifactUploadCmd(cmd *cobra.Command, args []string) (*ArtifactUploadCmd, error) {
+	dir, err := cmd.Flags().GetString(argUploadDir)
+	if err != nil {
+		return nil, err
+	}
+
+	artifactPath := ""
+	if len(args) == 1 {
+		artifactPath = args[0]
+	}
+	if (artifactPath == "") == (dir == "") {
+		return nil, errors.Errorf("Specify either an artifact or --%s", argUploadDir)
+	}
+
+	parallel, err := cmd.Flags().GetInt(argParallel)
+	if err != nil {
+		return nil, err
+	}
+	if parallel <= 0 {
+		return nil, errors.New("parallel argument must be larger than 0")
+	}
+
+	c, err := newArtifactFileUploadCmd(cmd, artifactPath)
+	if err != nil {
+		return nil, err
+	}
+	c.dir = dir
+	c.parallel = parallel
+	return c, nil
+}
+
+// newArtifactFileUploadCmd returns the command uploading the artifact file
+// at artifactPath, configured by the flags shared with artifacts create
+func newArtifactFileUploadCmd(
+	cmd *cobra.Command,
+	artifactPath string,
+) (*ArtifactUploadCmd, error) {
 	server := viper.GetString(argRootServer)
 	if server == "" {
 		return nil, errors.New("No server")
@@ -81,6 +158,11 @@ func NewArtifactUploadCmd(cmd *cobra.Command, args []string) (*ArtifactUploadCmd
 		return nil, err
 	}
 
+	verifyKey, err := getArtifactVerifyKey(cmd)
+	if err != nil {
+		return nil, err
+	}
+
 	token, err := getAuthToken(cmd)
 	if err != nil {
 		return nil, err
@@ -90,42 +172,202 @@ func NewArtifactUploadCmd(cmd *cobra.Command, args []string) (*ArtifactUploadCmd
 		server:          server,
 		description:     artifactDescription,
 		token:           token,
-		artifactPath:    args[0],
+		artifactPath:    artifactPath,
 		skipVerify:      skipVerify,
 		withoutProgress: withoutProgress,
 		direct:          direct,
+		verifyKey:       verifyKey,
+		output:          os.Stdout,
 	}, nil
 }
 
 func (c *ArtifactUploadCmd) Run() error {
 	client := deployments.NewClient(c.server, c.skipVerify)
-	if c.direct {
-		log.Infof("getting direct link.\n")
-		link, err := client.DirectDownloadLink(c.token)
+	if c.dir != "" {
+		return c.uploadDir(client)
+	}
+
+	if err := c.upload(client, c.artifactPath, c.withoutProgress); err != nil {
+		return err
+	}
+
+	log.Info("upload successful")
+
+	return nil
+}
+
+// upload verifies the signature of an artifact, if required, and uploads
+// it to the server
+func (c *ArtifactUploadCmd) upload(
+	client *deployments.Client,
+	artifactPath string,
+	noProgress bool,
+) error {
+	if c.verifyKey != nil {
+		err := deployments.VerifyArtifactSignature(artifactPath, c.verifyKey)
 		if err != nil {
-			return errors.Wrap(err, "failed to get the direct pre-signed URL")
+			return err
 		}
+		log.Info("artifact signature verified")
+	}
 
-		log.Infof("uploading the artifact.\n")
-		err = client.DirectUpload(
+	if c.direct {
+		statePath, err := getUploadStatePath(artifactPath)
+		if err != nil {
+			return err
+		}
+		err = client.ResumableDirectUpload(
 			c.token,
-			c.artifactPath,
-			link.ArtifactID,
-			link.Uri,
-			link.Header,
-			c.withoutProgress,
+			artifactPath,
+			statePath,
+			noProgress,
 		)
 		if err != nil {
 			return errors.Wrap(err, "failed to upload the artifact")
 		}
-	} else {
-		err := client.UploadArtifact(c.description, c.artifactPath, c.token, c.withoutProgress)
-		if err != nil {
-			return err
-		}
+		return nil
 	}
+	return client.UploadArtifact(c.description, artifactPath, c.token, noProgress)
+}
 
-	log.Info("upload successful")
+type uploadResult struct {
+	path   string
+	status string
+	err    error
+}
+
+// uploadDir uploads the artifacts of the directory which don't exist on
+// the server, with at most c.parallel concurrent uploads, and prints a
+// summary of the results
+func (c *ArtifactUploadCmd) uploadDir(client *deployments.Client) error {
+	paths, err := filepath.Glob(filepath.Join(c.dir, "*.mender"))
+	if err != nil {
+		return err
+	} else if len(paths) == 0 {
+		return errors.Errorf("No artifacts found in %s", c.dir)
+	}
+
+	artifacts, err := client.ListAllArtifacts(c.token)
+	if err != nil {
+		return errors.Wrap(err, "unable to list the artifacts")
+	}
+	index := artifactIndex{}
+	for _, a := range artifacts {
+		index.add(a.Name, a.DeviceTypesCompatible)
+	}
+	var indexMutex sync.Mutex
+
+	results := make([]uploadResult, len(paths))
+	sem := make(chan struct{}, c.parallel)
+	wg := &sync.WaitGroup{}
+	for i, path := range paths {
+		results[i].path = path
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			name, deviceTypes, err := readArtifactHeader(path)
+			if err != nil {
+				results[i].status, results[i].err = uploadStatusFailed, err
+				return
+			}
+			// also skips the duplicates in the directory itself
+			indexMutex.Lock()
+			exists := index.contains(name, deviceTypes)
+			if !exists {
+				index.add(name, deviceTypes)
+			}
+			indexMutex.Unlock()
+			if exists {
+				log.Verbf("skipping %s: artifact %s exists", path, name)
+				results[i].status = uploadStatusSkipped
+				return
+			}
+
+			err = c.upload(client, path, true)
+			switch {
+			case errors.Is(err, deployments.ErrArtifactExists):
+				results[i].status = uploadStatusSkipped
+			case err != nil:
+				results[i].status, results[i].err = uploadStatusFailed, err
+			default:
+				log.Infof("uploaded %s\n", path)
+				results[i].status = uploadStatusUploaded
+			}
+		}()
+	}
+	wg.Wait()
 
+	failed := 0
+	w := tabwriter.NewWriter(c.output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tSTATUS\tERROR")
+	for _, r := range results {
+		errStr := ""
+		if r.err != nil {
+			errStr = r.err.Error()
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", filepath.Base(r.path), r.status, errStr)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d artifacts failed to upload", failed, len(paths))
+	}
 	return nil
 }
+
+// readArtifactHeader returns the name and the compatible device types of
+// an artifact, reading only its header and not the payloads
+func readArtifactHeader(path string) (string, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Unable to open the artifact")
+	}
+	defer f.Close()
+	ar := areader.NewReader(f)
+	if err := ar.ReadArtifactHeaders(); err != nil {
+		return "", nil, errors.Wrap(err, "Unable to read the artifact")
+	}
+	return ar.GetArtifactName(), ar.GetCompatibleDevices(), nil
+}
+
+// artifactIndex holds the device types of the artifacts, by name. The
+// server refuses an artifact if one with the same name shares any of its
+// device types.
+type artifactIndex map[string]map[string]bool
+
+func (idx artifactIndex) add(name string, deviceTypes []string) {
+	if idx[name] == nil {
+		idx[name] = map[string]bool{}
+	}
+	for _, deviceType := range deviceTypes {
+		idx[name][deviceType] = true
+	}
+}
+
+func (idx artifactIndex) contains(name string, deviceTypes []string) bool {
+	for _, deviceType := range deviceTypes {
+		if idx[name][deviceType] {
+			return true
+		}
+	}
+	return false
+}
+
+// getUploadStatePath returns the path of the file saving the state of the
+// resumable direct upload of an artifact
+func getUploadStatePath(artifactPath string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.Wrap(err, "Not able to determine users cache dir")
+	}
+	absPath, err := filepath.Abs(artifactPath)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(absPath))
+	return filepath.Join(cacheDir, "mender", "uploads", hex.EncodeToString(sum[:])+".json"), nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"testing"
+)
+
+func TestArtifactIndex(t *testing.T) {
+	t.Parallel()
+	index := artifactIndex{}
+	index.add("release-1", []string{"rpi4", "rpi5"})
+	index.add("release-2", []string{"rpi4"})
+
+	testCases := map[string]struct {
+		name        string
+		deviceTypes []string
+		contains    bool
+	}{
+		"same device types": {
+			name:        "release-1",
+			deviceTypes: []string{"rpi4", "rpi5"},
+			contains:    true,
+		},
+		"shared device type": {
+			name:        "release-2",
+			deviceTypes: []string{"rpi4", "rpi5"},
+			contains:    true,
+		},
+		"other device type": {
+			name:        "release-2",
+			deviceTypes: []string{"rpi5"},
+		},
+		"other name": {
+			name:        "release-3",
+			deviceTypes: []string{"rpi4"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if index.contains(tc.name, tc.deviceTypes) != tc.contains {
+				t.Errorf("Expected contains to be %t", tc.contains)
+			}
+		})
+	}
+}
@@ -14,11 +14,21 @@
 package cmd
 
 import (
+	"os"
+
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 const (
 	argWithoutProgress = "no-progress"
+	argVerifyKey       = "verify-key"
+
+	// configuration file keys of the default verification key, and of
+	// the option making the signature verification mandatory
+	configArtifactVerifyKey            = "artifact-verify-key"
+	configArtifactVerificationRequired = "artifact-verification-required"
 )
 
 var artifactsCmd = &cobra.Command{
@@ -32,4 +42,32 @@ func init() {
 	artifactsCmd.AddCommand(artifactsListCmd)
 	artifactsCmd.AddCommand(artifactDeleteCmd)
 	artifactsCmd.AddCommand(artifactDownloadCmd)
+	artifactsCmd.AddCommand(artifactInspectCmd)
+	artifactsCmd.AddCommand(artifactCreateCmd)
+	artifactsCmd.AddCommand(artifactPruneCmd)
+}
+
+// getArtifactVerifyKey returns the public key verifying the artifact
+// signatures, from the command line or the configuration file, or nil if
+// the signatures are not verified
+func getArtifactVerifyKey(cmd *cobra.Command) ([]byte, error) {
+	keyPath, err := cmd.Flags().GetString(argVerifyKey)
+	if err != nil {
+		return nil, err
+	}
+	if keyPath == "" {
+		keyPath = viper.GetString(configArtifactVerifyKey)
+	}
+	if keyPath == "" {
+		if viper.GetBool(configArtifactVerificationRequired) {
+			return nil, errors.Errorf("The artifact signature verification is required: "+
+				"specify the public key with --%s", argVerifyKey)
+		}
+		return nil, nil
+	}
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read the verification key")
+	}
+	return key, nil
 }
@@ -0,0 +1,93 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// TestGetArtifactVerifyKey sets the global configuration, so it doesn't run
+// in parallel with the other tests
+func TestGetArtifactVerifyKey(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(keyPath, []byte("public key"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		viper.Set(configArtifactVerifyKey, "")
+		viper.Set(configArtifactVerificationRequired, false)
+	})
+
+	testCases := map[string]struct {
+		flag     string
+		config   string
+		required bool
+		key      string
+		err      bool
+	}{
+		"not verified": {},
+		"key from the command line": {
+			flag: keyPath,
+			key:  "public key",
+		},
+		"key from the configuration": {
+			config: keyPath,
+			key:    "public key",
+		},
+		"required without key": {
+			required: true,
+			err:      true,
+		},
+		"required with key": {
+			config:   keyPath,
+			required: true,
+			key:      "public key",
+		},
+		"missing key file": {
+			flag: filepath.Join(t.TempDir(), "missing.pem"),
+			err:  true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			viper.Set(configArtifactVerifyKey, tc.config)
+			viper.Set(configArtifactVerificationRequired, tc.required)
+			cmd := &cobra.Command{}
+			cmd.Flags().String(argVerifyKey, "", "")
+			if tc.flag != "" {
+				if err := cmd.Flags().Set(argVerifyKey, tc.flag); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			key, err := getArtifactVerifyKey(cmd)
+			if tc.err {
+				if err == nil {
+					t.Error("Expected an error, got nil")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %s", err.Error())
+			}
+			if string(key) != tc.key || (tc.key == "" && key != nil) {
+				t.Errorf("Unexpected key: %q, expected %q", key, tc.key)
+			}
+		})
+	}
+}
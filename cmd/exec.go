@@ -0,0 +1,399 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/sys/unix"
+
+	"github.com/mendersoftware/mender-cli/client/deviceconnect"
+	"github.com/mendersoftware/mender-cli/client/inventory"
+)
+
+const (
+	argTimeout   = "timeout"
+	argGroup     = "group"
+	argParallel  = "parallel"
+	argOutputDir = "output-dir"
+
+	execDefaultParallel = 10
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec [flags] DEVICE_ID [DEVICE_ID...] -- COMMAND [ARGS...]",
+	Short: "Run a command on one or more devices and return its exit status",
+	Long: "Run a command non-interactively in a shell session on a device.\n\n" +
+		"The command and its arguments are joined with spaces and interpreted\n" +
+		"by the device's shell, like ssh does. The standard output and error\n" +
+		"of the command are written to the standard output, and mender-cli\n" +
+		"exits with the exit status of the command.\n\n" +
+		"When more than one device is given, or the devices are selected with\n" +
+		"--group, the command runs on all of them concurrently. Each line of\n" +
+		"output is prefixed with the device ID, unless --output-dir is given,\n" +
+		"and a summary of the exit statuses and durations is printed at the\n" +
+		"end. In this case mender-cli exits with status 1 if the command\n" +
+		"failed on any device.",
+	Example: "  mender-cli exec DEVICE_ID -- df -h\n" +
+		"  mender-cli exec DEVICE_ID --timeout 30s -- 'systemctl is-active mender-connect'\n" +
+		"  mender-cli exec --group production --parallel 20 -- df -h",
+	Args: cobra.MinimumNArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewExecCmd(c, args)
+		CheckErr(err)
+		exitCode, err := cmd.Run()
+		CheckErr(err)
+		os.Exit(exitCode)
+	},
+}
+
+func init() {
+	execCmd.Flags().DurationP(argTimeout, "", 0,
+		"maximum time to wait for the command to complete (0 for no limit)")
+	execCmd.Flags().StringP(argGroup, "g", "", "run the command on all the devices in the group")
+	execCmd.Flags().IntP(argParallel, "p", execDefaultParallel,
+		"maximum number of devices to run the command on concurrently")
+	execCmd.Flags().StringP(argOutputDir, "", "",
+		"write the output of each device to DEVICE_ID.log in this directory")
+}
+
+// ExecCmd handles the exec command
+type ExecCmd struct {
+	server     string
+	token      string
+	skipVerify bool
+	deviceIDs  []string
+	group      string
+	command    string
+	timeout    time.Duration
+	parallel   int
+	outputDir  string
+	output     io.Writer
+}
+
+// NewExecCmd returns a new ExecCmd
+func NewExecCmd(cmd *cobra.Command, args []string) (*ExecCmd, error) {
+	server := viper.GetString(argRootServer)
+	if server == "" {
+		return nil, errors.New("No server")
+	}
+
+	skipVerify, err := cmd.Flags().GetBool(argRootSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := cmd.Flags().GetDuration(argTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := cmd.Flags().GetString(argGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	parallel, err := cmd.Flags().GetInt(argParallel)
+	if err != nil {
+		return nil, err
+	}
+	if parallel <= 0 {
+		return nil, errors.New("parallel argument must be larger than 0")
+	}
+
+	outputDir, err := cmd.Flags().GetString(argOutputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceIDs, command, err := parseExecArgs(args, cmd.ArgsLenAtDash(), group)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := getAuthToken(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExecCmd{
+		server:     server,
+		token:      token,
+		skipVerify: skipVerify,
+		deviceIDs:  deviceIDs,
+		group:      group,
+		command:    command,
+		timeout:    timeout,
+		parallel:   parallel,
+		outputDir:  outputDir,
+		output:     os.Stdout,
+	}, nil
+}
+
+// parseExecArgs splits the arguments in the device IDs and the command; the
+// device IDs precede '--', at index dash. Without it, the first argument is
+// the device ID unless the devices are selected by group.
+func parseExecArgs(args []string, dash int, group string) ([]string, string, error) {
+	var deviceIDs, commandArgs []string
+	if dash >= 0 {
+		deviceIDs, commandArgs = args[:dash], args[dash:]
+	} else if group != "" {
+		commandArgs = args
+	} else if len(args) > 0 {
+		deviceIDs, commandArgs = args[:1], args[1:]
+	}
+	if len(deviceIDs) == 0 && group == "" {
+		return nil, "", errors.New("No device specified")
+	}
+
+	command := strings.TrimSpace(strings.Join(commandArgs, " "))
+	if command == "" {
+		return nil, "", errors.New("No command specified")
+	}
+	return deviceIDs, command, nil
+}
+
+// Run executes the command, returning the exit status of the remote command
+func (c *ExecCmd) Run() (int, error) {
+	ctx, cancelContext := context.WithCancel(context.Background())
+	defer cancelContext()
+	if c.timeout > 0 {
+		ctx, cancelContext = context.WithTimeout(ctx, c.timeout)
+		defer cancelContext()
+	}
+
+	// handle CTRL+C and signals
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, unix.SIGINT, unix.SIGTERM)
+	defer signal.Stop(quit)
+	go func() {
+		select {
+		case <-quit:
+			cancelContext()
+		case <-ctx.Done():
+		}
+	}()
+
+	deviceIDs := c.deviceIDs
+	if c.group != "" {
+		client := inventory.NewClient(c.server, c.skipVerify)
+		groupDeviceIDs, err := client.ListGroupDevices(c.token, c.group)
+		if err != nil {
+			return -1, errors.Wrap(err, "unable to get the devices in the group")
+		}
+		deviceIDs = append(deviceIDs, groupDeviceIDs...)
+		if len(deviceIDs) == 0 {
+			return -1, errors.Errorf("no devices in group %q", c.group)
+		}
+	}
+
+	if len(deviceIDs) == 1 && c.group == "" && c.outputDir == "" {
+		exitCode, _, err := c.runOnDevice(ctx, deviceIDs[0], c.output)
+		return exitCode, err
+	}
+	return c.runOnDevices(ctx, deviceIDs)
+}
+
+// execResult is the result of running the command on a device
+type execResult struct {
+	deviceID string
+	exitCode int
+	duration time.Duration
+	err      error
+}
+
+// runOnDevices runs the command on all the devices, with at most
+// c.parallel concurrent sessions, and prints a summary of the results
+func (c *ExecCmd) runOnDevices(ctx context.Context, deviceIDs []string) (int, error) {
+	if c.outputDir != "" {
+		if err := os.MkdirAll(c.outputDir, 0755); err != nil {
+			return -1, err
+		}
+	}
+
+	results := make([]execResult, len(deviceIDs))
+	outputMutex := &sync.Mutex{}
+	sem := make(chan struct{}, c.parallel)
+	wg := &sync.WaitGroup{}
+	for i, deviceID := range deviceIDs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = c.runOnDeviceOutput(ctx, deviceID, outputMutex)
+		}()
+	}
+	wg.Wait()
+
+	exitCode := 0
+	w := tabwriter.NewWriter(c.output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DEVICE\tEXIT STATUS\tDURATION\tERROR")
+	for _, r := range results {
+		status, errStr := strconv.Itoa(r.exitCode), ""
+		if r.err != nil {
+			status, errStr = "-", r.err.Error()
+		}
+		if r.err != nil || r.exitCode != 0 {
+			exitCode = 1
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			r.deviceID, status, r.duration.Round(time.Millisecond), errStr)
+	}
+	w.Flush()
+	return exitCode, nil
+}
+
+// runOnDeviceOutput runs the command on a device, writing the output either
+// to a file in the output directory or prefixed with the device ID
+func (c *ExecCmd) runOnDeviceOutput(
+	ctx context.Context,
+	deviceID string,
+	outputMutex *sync.Mutex,
+) execResult {
+	result := execResult{deviceID: deviceID}
+	if c.outputDir != "" {
+		f, err := os.Create(filepath.Join(c.outputDir, deviceID+".log"))
+		if err != nil {
+			result.err = err
+			return result
+		}
+		defer f.Close()
+		result.exitCode, result.duration, result.err = c.runOnDevice(ctx, deviceID, f)
+	} else {
+		w := &prefixWriter{
+			prefix: []byte(deviceID + ": "),
+			w:      c.output,
+			mutex:  outputMutex,
+		}
+		result.exitCode, result.duration, result.err = c.runOnDevice(ctx, deviceID, w)
+		if err := w.Flush(); err != nil && result.err == nil {
+			result.err = err
+		}
+	}
+	return result
+}
+
+// runOnDevice runs the command on a device, returning its exit status and
+// the time it took
+func (c *ExecCmd) runOnDevice(
+	ctx context.Context,
+	deviceID string,
+	w io.Writer,
+) (int, time.Duration, error) {
+	start := time.Now()
+	if err := ctx.Err(); err != nil {
+		return -1, 0, err
+	}
+	// stop the ping-pong health-check when the session on this device ends
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	client, err := connectDevice(ctx, c.server, c.token, c.skipVerify, deviceID)
+	if err != nil {
+		return -1, time.Since(start), err
+	}
+	defer client.Close()
+
+	exitCode, err := client.RunShellCommand(ctx, c.command, w)
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = errors.New("timed out waiting for the command to complete")
+	}
+	return exitCode, time.Since(start), err
+}
+
+// prefixWriter writes complete lines prefixed with a prefix to the
+// underlying writer, which is shared with other writers
+type prefixWriter struct {
+	prefix []byte
+	w      io.Writer
+	mutex  *sync.Mutex
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+	i := bytes.LastIndexByte(p.buf, '\n')
+	if i < 0 {
+		return len(data), nil
+	}
+	if err := p.write(p.buf[:i+1]); err != nil {
+		return 0, err
+	}
+	p.buf = p.buf[i+1:]
+	return len(data), nil
+}
+
+// Flush writes the last incomplete line, if any
+func (p *prefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	err := p.write(append(p.buf, '\n'))
+	p.buf = nil
+	return err
+}
+
+func (p *prefixWriter) write(lines []byte) error {
+	var out []byte
+	for len(lines) > 0 {
+		i := bytes.IndexByte(lines, '\n')
+		out = append(out, p.prefix...)
+		out = append(out, lines[:i+1]...)
+		lines = lines[i+1:]
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	_, err := p.w.Write(out)
+	return err
+}
+
+// connectDevice checks that the device is connected, connects to the
+// websocket and starts the ping-pong connection health-check
+func connectDevice(
+	ctx context.Context,
+	server, token string,
+	skipVerify bool,
+	deviceID string,
+) (*deviceconnect.Client, error) {
+	client := deviceconnect.NewClient(server, token, skipVerify)
+
+	device, err := client.GetDevice(deviceID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the device")
+	} else if device.Status != deviceconnect.CONNECTED {
+		return nil, errors.New("the device is not connected")
+	}
+
+	err = client.Connect(deviceID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	go client.PingPong(ctx)
+	return client, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestParseExecArgs(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]struct {
+		args      []string
+		dash      int
+		group     string
+		deviceIDs []string
+		command   string
+		err       string
+	}{
+		"device and command": {
+			args:      []string{"dev1", "uname", "-a"},
+			dash:      -1,
+			deviceIDs: []string{"dev1"},
+			command:   "uname -a",
+		},
+		"devices before dash": {
+			args:      []string{"dev1", "dev2", "uname", "-a"},
+			dash:      2,
+			deviceIDs: []string{"dev1", "dev2"},
+			command:   "uname -a",
+		},
+		"group": {
+			args:    []string{"uname", "-a"},
+			dash:    -1,
+			group:   "production",
+			command: "uname -a",
+		},
+		"group and devices": {
+			args:      []string{"dev1", "uptime"},
+			dash:      1,
+			group:     "production",
+			deviceIDs: []string{"dev1"},
+			command:   "uptime",
+		},
+		"no device": {
+			args: []string{"uptime"},
+			dash: 0,
+			err:  "No device specified",
+		},
+		"no command": {
+			args: []string{"dev1"},
+			dash: -1,
+			err:  "No command specified",
+		},
+		"blank command": {
+			args: []string{"dev1", "dev2", " "},
+			dash: 2,
+			err:  "No command specified",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			deviceIDs, command, err := parseExecArgs(tc.args, tc.dash, tc.group)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("Expected error %q, got %v", tc.err, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %s", err.Error())
+			}
+			if !reflect.DeepEqual(deviceIDs, tc.deviceIDs) {
+				t.Errorf("Unexpected device IDs: %v, expected %v", deviceIDs, tc.deviceIDs)
+			}
+			if command != tc.command {
+				t.Errorf("Unexpected command: %q, expected %q", command, tc.command)
+			}
+		})
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]struct {
+		chunks []string
+		output string
+	}{
+		"complete lines": {
+			chunks: []string{"foo\nbar\n"},
+			output: "dev1: foo\ndev1: bar\n",
+		},
+		"split lines": {
+			chunks: []string{"fo", "o\nba", "r\n"},
+			output: "dev1: foo\ndev1: bar\n",
+		},
+		"incomplete last line": {
+			chunks: []string{"foo\nbar"},
+			output: "dev1: foo\ndev1: bar\n",
+		},
+		"empty lines": {
+			chunks: []string{"\n\nfoo\n"},
+			output: "dev1: \ndev1: \ndev1: foo\n",
+		},
+		"no output": {},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			w := &prefixWriter{prefix: []byte("dev1: "), w: &out, mutex: &sync.Mutex{}}
+			for _, chunk := range tc.chunks {
+				n, err := w.Write([]byte(chunk))
+				if err != nil {
+					t.Fatalf("Unexpected error: %s", err.Error())
+				} else if n != len(chunk) {
+					t.Fatalf("Expected %d bytes written, got %d", len(chunk), n)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Unexpected error: %s", err.Error())
+			}
+			if out.String() != tc.output {
+				t.Errorf("Unexpected output: %q, expected %q", out.String(), tc.output)
+			}
+		})
+	}
+}
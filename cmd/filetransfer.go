@@ -26,6 +26,11 @@ import (
 
 const (
 	deviceDelimiter = ":"
+
+	argVerify = "verify"
+
+	// number of transfer attempts when the verification fails
+	fileTransferMaxAttempts = 3
 )
 
 var fileTransferCmd = &cobra.Command{
@@ -40,12 +45,22 @@ var fileTransferCmd = &cobra.Command{
 	},
 }
 
+func init() {
+	fileTransferCmd.Flags().BoolP(argWithoutProgress, "", false, "disable progress bar")
+	fileTransferCmd.Flags().BoolP(argVerify, "", false,
+		"verify the transferred file, retrying on mismatch; the SHA-256 checksum is "+
+			"compared if the device reports it, otherwise only the size, as with the "+
+			"current mender-connect")
+}
+
 type FileTransferCmd struct {
-	server      string
-	skipVerify  bool
-	source      string
-	destination string
-	token       string
+	server          string
+	skipVerify      bool
+	source          string
+	destination     string
+	token           string
+	withoutProgress bool
+	verify          bool
 }
 
 func NewFileTransfer(cmd *cobra.Command, args []string) (*FileTransferCmd, error) {
@@ -59,17 +74,29 @@ func NewFileTransfer(cmd *cobra.Command, args []string) (*FileTransferCmd, error
 		return nil, err
 	}
 
+	withoutProgress, err := cmd.Flags().GetBool(argWithoutProgress)
+	if err != nil {
+		return nil, err
+	}
+
+	verify, err := cmd.Flags().GetBool(argVerify)
+	if err != nil {
+		return nil, err
+	}
+
 	token, err := getAuthToken(cmd)
 	if err != nil {
 		return nil, err
 	}
 
 	return &FileTransferCmd{
-		server:      server,
-		skipVerify:  skipVerify,
-		token:       token,
-		source:      args[0],
-		destination: args[1],
+		server:          server,
+		skipVerify:      skipVerify,
+		token:           token,
+		source:          args[0],
+		destination:     args[1],
+		withoutProgress: withoutProgress,
+		verify:          verify,
 	}, nil
 }
 
@@ -102,7 +129,13 @@ func (c *FileTransferCmd) upload() error {
 		return err
 	}
 	client := deviceconnect.NewFileTransferClient(c.server, c.token, c.skipVerify)
-	if err = client.Upload(c.source, d); err != nil {
+	err = c.transfer(func() error {
+		if err := client.Upload(c.source, d, c.withoutProgress); err != nil {
+			return err
+		}
+		return c.verifyFile(client, c.source, d)
+	})
+	if err != nil {
 		return err
 	}
 	log.Infof("Successfully uploaded the file %q to device %q at location %q\n",
@@ -130,10 +163,43 @@ func (c *FileTransferCmd) download() error {
 		return err
 	}
 	client := deviceconnect.NewFileTransferClient(c.server, c.token, c.skipVerify)
-	if err = client.Download(d, c.destination); err != nil {
+	err = c.transfer(func() error {
+		if err := client.Download(d, c.destination, c.withoutProgress); err != nil {
+			return err
+		}
+		return c.verifyFile(client, c.destination, d)
+	})
+	if err != nil {
 		return err
 	}
 	log.Infof("Successfully downloaded the file: %q from device %q to %q\n",
 		d.DevicePath, d.DeviceID, c.source)
 	return nil
 }
+
+// transfer runs the transfer function, retrying it when the verification
+// of the transferred file fails
+func (c *FileTransferCmd) transfer(f func() error) error {
+	var err error
+	for attempt := 1; attempt <= fileTransferMaxAttempts; attempt++ {
+		err = f()
+		if !errors.Is(err, deviceconnect.ErrChecksumMismatch) &&
+			!errors.Is(err, deviceconnect.ErrSizeMismatch) {
+			return err
+		}
+		log.Errf("Verification failed (attempt %d/%d): %s\n",
+			attempt, fileTransferMaxAttempts, err)
+	}
+	return err
+}
+
+func (c *FileTransferCmd) verifyFile(
+	client *deviceconnect.Client,
+	localPath string,
+	d *deviceconnect.DeviceSpec,
+) error {
+	if !c.verify {
+		return nil
+	}
+	return client.VerifyFile(localPath, d)
+}
@@ -0,0 +1,261 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mendersoftware/mender-cli/client/deviceconnect"
+)
+
+const (
+	argRecursive = "recursive"
+	argParents   = "parents"
+
+	fsOpStat   = "stat"
+	fsOpList   = "ls"
+	fsOpRemove = "rm"
+	fsOpMkdir  = "mkdir"
+)
+
+var fsCmd = &cobra.Command{
+	Use:   "fs",
+	Short: "Inspect and manage files on a device.",
+	Long: "Inspect and manage files on a device without opening a terminal.\n\n" +
+		"Files are specified as DEVICE_ID:PATH. The stat command uses the\n" +
+		"file transfer protocol, while ls, rm and mkdir run the corresponding\n" +
+		"commands in a shell session on the device.",
+	ValidArgs: []string{fsOpStat, fsOpList, fsOpRemove, fsOpMkdir},
+}
+
+var fsStatCmd = &cobra.Command{
+	Use:   "stat DEVICE_ID:PATH",
+	Short: "Show the mode, owner, size and modification time of a file on a device.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewFsCmd(c, args, fsOpStat)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+var fsListCmd = &cobra.Command{
+	Use:   "ls DEVICE_ID:PATH",
+	Short: "List the contents of a directory on a device.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewFsCmd(c, args, fsOpList)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+var fsRemoveCmd = &cobra.Command{
+	Use:   "rm [flags] DEVICE_ID:PATH",
+	Short: "Remove a file or directory on a device.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewFsCmd(c, args, fsOpRemove)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+var fsMkdirCmd = &cobra.Command{
+	Use:   "mkdir [flags] DEVICE_ID:PATH",
+	Short: "Create a directory on a device.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewFsCmd(c, args, fsOpMkdir)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+func init() {
+	fsStatCmd.Flags().BoolP(argRawMode, "r", false, "fs stat raw mode (json)")
+	fsListCmd.Flags().BoolP(argRawMode, "r", false, "fs ls raw mode (json)")
+	fsRemoveCmd.Flags().BoolP(argRecursive, "R", false,
+		"remove directories and their contents recursively")
+	fsMkdirCmd.Flags().BoolP(argParents, "p", false,
+		"create parent directories as needed")
+
+	fsCmd.AddCommand(fsStatCmd)
+	fsCmd.AddCommand(fsListCmd)
+	fsCmd.AddCommand(fsRemoveCmd)
+	fsCmd.AddCommand(fsMkdirCmd)
+}
+
+// FsCmd handles the fs sub-commands
+type FsCmd struct {
+	server     string
+	skipVerify bool
+	token      string
+	op         string
+	deviceSpec *deviceconnect.DeviceSpec
+	recursive  bool
+	parents    bool
+	rawMode    bool
+	output     io.Writer
+}
+
+// NewFsCmd returns a new FsCmd
+func NewFsCmd(cmd *cobra.Command, args []string, op string) (*FsCmd, error) {
+	server := viper.GetString(argRootServer)
+	if server == "" {
+		return nil, errors.New("No server")
+	}
+
+	skipVerify, err := cmd.Flags().GetBool(argRootSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	var recursive, parents, rawMode bool
+	switch op {
+	case fsOpStat, fsOpList:
+		rawMode, err = cmd.Flags().GetBool(argRawMode)
+	case fsOpRemove:
+		recursive, err = cmd.Flags().GetBool(argRecursive)
+	case fsOpMkdir:
+		parents, err = cmd.Flags().GetBool(argParents)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deviceSpec, err := deviceSpecification(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := getAuthToken(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FsCmd{
+		server:     server,
+		skipVerify: skipVerify,
+		token:      token,
+		op:         op,
+		deviceSpec: deviceSpec,
+		recursive:  recursive,
+		parents:    parents,
+		rawMode:    rawMode,
+		output:     os.Stdout,
+	}, nil
+}
+
+// Run executes the command
+func (c *FsCmd) Run() error {
+	ctx, cancelContext := context.WithCancel(context.Background())
+	defer cancelContext()
+
+	client := deviceconnect.NewClient(c.server, c.token, c.skipVerify)
+
+	// check if the device is connected
+	device, err := client.GetDevice(c.deviceSpec.DeviceID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get the device")
+	} else if device.Status != deviceconnect.CONNECTED {
+		return errors.New("the device is not connected")
+	}
+
+	if c.op == fsOpStat {
+		fileInfo, err := client.StatFile(c.deviceSpec)
+		if err != nil {
+			return err
+		}
+		if c.rawMode {
+			return printJSON(c.output, fileInfo)
+		}
+		printFileInfo(c.output, fileInfo)
+		return nil
+	}
+
+	// connect to the websocket and start the ping-pong connection health-check
+	err = client.Connect(c.deviceSpec.DeviceID, c.token)
+	if err != nil {
+		return err
+	}
+
+	go client.PingPong(ctx)
+	defer client.Close()
+
+	switch c.op {
+	case fsOpList:
+		files, err := client.ListDir(ctx, c.deviceSpec.DevicePath)
+		if err != nil {
+			return err
+		}
+		if c.rawMode {
+			return printJSON(c.output, files)
+		}
+		printFileList(c.output, files)
+	case fsOpRemove:
+		return client.Remove(ctx, c.deviceSpec.DevicePath, c.recursive)
+	case fsOpMkdir:
+		return client.MakeDir(ctx, c.deviceSpec.DevicePath, c.parents)
+	default:
+		return errors.New("unknown operation: " + c.op)
+	}
+	return nil
+}
+
+func printFileInfo(out io.Writer, f *deviceconnect.FileInfo) {
+	if f.Path != nil {
+		fmt.Fprintf(out, "Path: %s\n", *f.Path)
+	}
+	if f.Mode != nil {
+		fmt.Fprintf(out, "Mode: %s\n", os.FileMode(*f.Mode))
+	}
+	if f.UID != nil && f.GID != nil {
+		fmt.Fprintf(out, "Owner: %d:%d\n", *f.UID, *f.GID)
+	}
+	if f.Size != nil {
+		fmt.Fprintf(out, "Size: %d\n", *f.Size)
+	}
+	if f.ModTime != nil {
+		fmt.Fprintf(out, "Modified: %s\n", f.ModTime.Local().Format(time.RFC3339))
+	}
+	if f.Checksum != nil {
+		fmt.Fprintf(out, "Checksum: %s\n", *f.Checksum)
+	}
+}
+
+func printFileList(out io.Writer, files []deviceconnect.FileInfo) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	for _, f := range files {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t %s\n",
+			os.FileMode(*f.Mode),
+			*f.UID,
+			*f.GID,
+			*f.Size,
+			f.ModTime.Local().Format("2006-01-02 15:04"),
+			path.Base(*f.Path),
+		)
+	}
+	w.Flush()
+}
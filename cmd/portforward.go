@@ -21,6 +21,8 @@ import (
 	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mendersoftware/go-lib-micro/ws"
@@ -36,13 +38,22 @@ import (
 
 const (
 	argBindHost    = "bind"
+	argStdio       = "stdio"
+	argSOCKS5      = "socks5"
+	argStatus      = "status"
+	argStatsAddr   = "stats-addr"
+	argProfile     = "profile"
+	argMaxDuration = "max-duration"
+	argIdleTimeout = "idle-timeout"
 	readBuffLength = 4096
 	localhost      = "127.0.0.1"
+
+	configPortForwardProfiles = "port-forward-profiles"
 )
 
 var portForwardCmd = &cobra.Command{
-	Use: "port-forward DEVICE_ID [tcp|udp/]LOCAL_PORT[:REMOTE_PORT]" +
-		" [[tcp|udp/]LOCAL_PORT[:REMOTE_PORT]...]",
+	Use: "port-forward [flags] [DEVICE_ID [tcp|udp/]LOCAL_PORT[:REMOTE_PORT]" +
+		" [[tcp|udp/]LOCAL_PORT[:REMOTE_PORT]...] [unix:LOCAL_PATH:REMOTE_PORT...]]",
 	Short: "Forward one or more local ports to remote port(s) on the device",
 	Long: "This command supports both TCP and UDP port-forwarding.\n\n" +
 		"The port specification can be prefixed with \"tcp/\" or \"udp/\".\n" +
@@ -50,11 +61,51 @@ var portForwardCmd = &cobra.Command{
 		"REMOTE_PORT can also be specified in the form REMOTE_HOST:REMOTE_PORT, making\n" +
 		"it possible to port-forward to third hosts running in the device's network.\n" +
 		"In this case, the specification will be LOCAL_PORT:REMOTE_HOST:REMOTE_PORT.\n\n" +
-		"You can specify multiple port mapping specifications.",
+		"You can specify multiple port mapping specifications.\n\n" +
+		"A local Unix socket can be forwarded to a remote TCP port with the\n" +
+		"specification unix:LOCAL_PATH:[REMOTE_HOST:]REMOTE_PORT. The socket is\n" +
+		"only accessible by the current user, and it is removed on exit. Unix\n" +
+		"sockets on the device can't be forwarded, as the port forward protocol\n" +
+		"only supports TCP and UDP: they can be reached through a TCP proxy\n" +
+		"running on the device, like socat.\n\n" +
+		"With --stdio, the standard input and output are forwarded to a single\n" +
+		"remote TCP port, specified as [REMOTE_HOST:]REMOTE_PORT, without\n" +
+		"listening on any local port. This makes it possible to use mender-cli\n" +
+		"as an ssh ProxyCommand, and to run scp, rsync or ansible over it.\n\n" +
+		"With --socks5 LOCAL_PORT, a SOCKS5 proxy listens on the local port and\n" +
+		"forwards each connection to the host and port requested by the client,\n" +
+		"as seen from the device. In this case, the port mappings are optional.\n" +
+		"The proxy doesn't require authentication, and reports the connections as\n" +
+		"established before the device connects to the requested host.\n\n" +
+		"With --status, the number of connections, the bytes transferred and the\n" +
+		"errors are shown in a live status line, or printed periodically if the\n" +
+		"standard error is not a terminal. With --stats-addr, the same statistics\n" +
+		"are served for each port mapping in the Prometheus text format, on the\n" +
+		"/metrics path of the given local address.\n\n" +
+		"The port forwarding stops after --max-duration, which can be set to 0 to\n" +
+		"forward the ports until interrupted. With --idle-timeout, it also stops\n" +
+		"when no TCP connections are open and no data is forwarded for the given\n" +
+		"duration.\n\n" +
+		"With --profile NAME, the tunnels are read from the \"" + configPortForwardProfiles +
+		"\"\nsection of the configuration file, instead of the command line; each\n" +
+		"tunnel lists the device, the port mappings and optionally the binding\n" +
+		"host and the SOCKS5 proxy port. The tunnels to different devices run\n" +
+		"concurrently, each with its own connection. For example:\n\n" +
+		"  \"" + configPortForwardProfiles + "\": {\n" +
+		"    \"web\": [\n" +
+		"      {\"device\": \"DEVICE_ID\", \"mappings\": [\"8080:80\", \"udp/5353\"]},\n" +
+		"      {\"device\": \"DEVICE_ID_2\", \"bind\": \"0.0.0.0\", \"socks5\": 1080}\n" +
+		"    ]\n" +
+		"  }",
 	Example: "  mender-cli port-forward DEVICE_ID 8000:8000\n" +
 		"  mender-cli port-forward DEVICE_ID udp/8000:8000\n" +
-		"  mender-cli port-forward DEVICE_ID tcp/8000:192.168.1.1:8000",
-	Args: cobra.MinimumNArgs(2),
+		"  mender-cli port-forward DEVICE_ID tcp/8000:192.168.1.1:8000\n" +
+		"  mender-cli port-forward DEVICE_ID unix:/tmp/docker.sock:2375\n" +
+		"  mender-cli port-forward DEVICE_ID --socks5 1080\n" +
+		"  mender-cli port-forward DEVICE_ID 8000 --status --stats-addr 127.0.0.1:9100\n" +
+		"  mender-cli port-forward --profile web\n" +
+		"  ssh -o ProxyCommand=\"mender-cli port-forward --stdio %h 22\" root@DEVICE_ID",
+	Args: cobra.ArbitraryArgs,
 	Run: func(c *cobra.Command, args []string) {
 		cmd, err := NewPortForwardCmd(c, args)
 		CheckErr(err)
@@ -62,8 +113,12 @@ var portForwardCmd = &cobra.Command{
 	},
 }
 
+// default maximum duration of the port forwarding
 var portForwardMaxDuration = 24 * time.Hour
 
+// interval of the checks for the idle timeout
+var portForwardIdleCheckInterval = time.Second
+
 var errPortForwardNotImplemented = errors.New(
 	"port forward not implemented or enabled on the device",
 )
@@ -71,39 +126,146 @@ var errRestart = errors.New("restart")
 
 func init() {
 	portForwardCmd.Flags().StringP(argBindHost, "", localhost, "binding host")
+	portForwardCmd.Flags().Uint16P(argSOCKS5, "", 0,
+		"run a SOCKS5 proxy on this local port")
+	portForwardCmd.Flags().BoolP(argStdio, "", false,
+		"forward the standard input and output to a single remote TCP port")
+	portForwardCmd.Flags().BoolP(argStatus, "", false,
+		"show the port-forward statistics")
+	portForwardCmd.Flags().StringP(argStatsAddr, "", "",
+		"serve the port-forward statistics in the Prometheus text format on this address")
+	portForwardCmd.Flags().StringP(argProfile, "", "",
+		"run the tunnels of the profile with this name, from the configuration file")
+	portForwardCmd.Flags().DurationP(argMaxDuration, "", portForwardMaxDuration,
+		"maximum duration of the port forwarding, 0 for unlimited")
+	portForwardCmd.Flags().DurationP(argIdleTimeout, "", 0,
+		"stop the port forwarding after being idle for this duration, 0 to disable")
 }
 
 const (
-	protocolTCP = "tcp"
-	protocolUDP = "udp"
+	protocolTCP  = "tcp"
+	protocolUDP  = "udp"
+	protocolUnix = "unix"
 )
 
 type portMapping struct {
 	Protocol   string
 	LocalPort  uint16
+	LocalPath  string
 	RemoteHost string
 	RemotePort uint16
 }
 
 // PortForwardCmd handles the port-forward command
 type PortForwardCmd struct {
-	server       string
-	token        string
-	skipVerify   bool
+	server      string
+	token       string
+	skipVerify  bool
+	tunnels     []*portForwardTunnel
+	maxDuration time.Duration
+	idleTimeout time.Duration
+	status      bool
+	statsAddr   string
+	stats       []*portForwardStats
+}
+
+// portForwardTunnel forwards the port mappings of a single device, with
+// its own connection to the server and restart loop
+type portForwardTunnel struct {
+	cmd          *PortForwardCmd
 	deviceID     string
 	sessionID    string
 	bindingHost  string
 	portMappings []portMapping
-	recvChans    map[string]chan *ws.ProtoMsg
-	running      bool
-	stop         chan struct{}
-	err          error
+	stdio        bool
+	socks5Port   uint16
+	stats        []*portForwardStats
+	recvChans    *recvChannels
+}
+
+// portForwardRun is the state of a single run of a tunnel, shared by the
+// goroutines forwarding its messages; each restart starts a new run
+type portForwardRun struct {
+	running  atomic.Bool
+	stop     chan struct{}
+	stopOnce sync.Once
+	errMutex sync.Mutex
+	err      error
+}
+
+func newPortForwardRun() *portForwardRun {
+	r := &portForwardRun{
+		stop: make(chan struct{}),
+	}
+	r.running.Store(true)
+	return r
+}
+
+// Stop restarts the port forwarding; it never blocks, and can be called
+// more than once, also after the run is over
+func (r *portForwardRun) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+func (r *portForwardRun) setErr(err error) {
+	r.errMutex.Lock()
+	defer r.errMutex.Unlock()
+	r.err = err
+}
+
+func (r *portForwardRun) Err() error {
+	r.errMutex.Lock()
+	defer r.errMutex.Unlock()
+	return r.err
+}
+
+func newPortForwardTunnel(
+	cmd *PortForwardCmd,
+	deviceID string,
+	bindingHost string,
+	portMappings []portMapping,
+	stdio bool,
+	socks5Port uint16,
+) *portForwardTunnel {
+	// the statistics of each port mapping, followed by the SOCKS5 proxy
+	stats := make([]*portForwardStats, 0, len(portMappings)+1)
+	for _, portMapping := range portMappings {
+		name := portMapping.String()
+		if stdio {
+			name = fmt.Sprintf("stdio:%s:%d", portMapping.RemoteHost, portMapping.RemotePort)
+		}
+		stats = append(stats, newPortForwardStats(deviceID, name))
+	}
+	if socks5Port > 0 {
+		stats = append(stats,
+			newPortForwardStats(deviceID, fmt.Sprintf("socks5/%d", socks5Port)))
+	}
+	return &portForwardTunnel{
+		cmd:          cmd,
+		deviceID:     deviceID,
+		bindingHost:  bindingHost,
+		portMappings: portMappings,
+		stdio:        stdio,
+		socks5Port:   socks5Port,
+		stats:        stats,
+		recvChans:    newRecvChannels(),
+	}
 }
 
 func getPortMappings(args []string) ([]portMapping, error) {
 	var err error
 	portMappings := []portMapping{}
 	for _, arg := range args {
+		if strings.HasPrefix(arg, protocolUnix+":") {
+			portMapping, err := getUnixPortMapping(arg)
+			if err != nil {
+				return nil, err
+			}
+			portMappings = append(portMappings, portMapping)
+			continue
+		}
 		remoteHost := localhost
 		protocol := wspf.PortForwardProtocolTCP
 		if strings.Contains(arg, "/") {
@@ -150,6 +312,138 @@ func getPortMappings(args []string) ([]portMapping, error) {
 	return portMappings, nil
 }
 
+// portForwardProfileTunnel is a tunnel of a port-forward profile
+type portForwardProfileTunnel struct {
+	Device   string   `mapstructure:"device"`
+	Bind     string   `mapstructure:"bind"`
+	Mappings []string `mapstructure:"mappings"`
+	SOCKS5   uint16   `mapstructure:"socks5"`
+}
+
+// getPortForwardProfile returns the tunnels of the named profile; like all
+// the configuration keys, the profile names are case insensitive
+func getPortForwardProfile(config *viper.Viper, name string) ([]portForwardProfileTunnel, error) {
+	profiles := map[string][]portForwardProfileTunnel{}
+	if err := config.UnmarshalKey(configPortForwardProfiles, &profiles); err != nil {
+		return nil, errors.Wrap(err, "invalid port-forward profiles")
+	}
+	tunnels, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return nil, errors.Errorf("port-forward profile not found: %s", name)
+	} else if len(tunnels) == 0 {
+		return nil, errors.Errorf("port-forward profile %s has no tunnels", name)
+	}
+	for i, tunnel := range tunnels {
+		if tunnel.Device == "" {
+			return nil, errors.Errorf("port-forward profile %s: tunnel %d has no device",
+				name, i+1)
+		} else if len(tunnel.Mappings) == 0 && tunnel.SOCKS5 == 0 {
+			return nil, errors.Errorf("port-forward profile %s: device %s has no port mappings",
+				name, tunnel.Device)
+		}
+	}
+	return tunnels, nil
+}
+
+// recvChannels holds the channels receiving the messages from the device
+// for each connection, which are accessed by the forwarders and by the
+// processing of the incoming messages
+type recvChannels struct {
+	mutex sync.Mutex
+	chans map[string]*recvChannel
+}
+
+type recvChannel struct {
+	c chan *ws.ProtoMsg
+	// closed when the connection is removed
+	done chan struct{}
+}
+
+func newRecvChannels() *recvChannels {
+	return &recvChannels{
+		chans: map[string]*recvChannel{},
+	}
+}
+
+// Add registers a new connection, returning its channel
+func (r *recvChannels) Add(connectionID string, size int) chan *ws.ProtoMsg {
+	recvChan := &recvChannel{
+		c:    make(chan *ws.ProtoMsg, size),
+		done: make(chan struct{}),
+	}
+	r.mutex.Lock()
+	r.chans[connectionID] = recvChan
+	r.mutex.Unlock()
+	return recvChan.c
+}
+
+// Remove unregisters a connection; the messages still being sent to it
+// are discarded
+func (r *recvChannels) Remove(connectionID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if recvChan, ok := r.chans[connectionID]; ok {
+		delete(r.chans, connectionID)
+		close(recvChan.done)
+	}
+}
+
+// Send sends a message to a connection, if still registered
+func (r *recvChannels) Send(connectionID string, m *ws.ProtoMsg) {
+	r.mutex.Lock()
+	recvChan, ok := r.chans[connectionID]
+	r.mutex.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case recvChan.c <- m:
+	case <-recvChan.done:
+	}
+}
+
+// getUnixPortMapping parses the unix:LOCAL_PATH:[REMOTE_HOST:]REMOTE_PORT
+// specification of a local Unix socket forwarded to a remote TCP port
+func getUnixPortMapping(arg string) (portMapping, error) {
+	parts := strings.Split(strings.TrimPrefix(arg, protocolUnix+":"), ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+		return portMapping{}, errors.New("invalid port mapping: " + arg)
+	}
+	remoteHost := localhost
+	if len(parts) == 3 {
+		remoteHost = parts[1]
+	}
+	port := parts[len(parts)-1]
+	remotePort, err := strconv.Atoi(port)
+	if err != nil || remotePort <= 0 || remotePort > 65535 {
+		return portMapping{}, errors.New("invalid port number: " + port)
+	}
+	return portMapping{
+		Protocol:   protocolUnix,
+		LocalPath:  parts[0],
+		RemoteHost: remoteHost,
+		RemotePort: uint16(remotePort),
+	}, nil
+}
+
+// getStdioPortMapping parses the [REMOTE_HOST:]REMOTE_PORT specification of
+// the remote port forwarded with --stdio
+func getStdioPortMapping(arg string) (portMapping, error) {
+	remoteHost := localhost
+	if i := strings.LastIndex(arg, ":"); i >= 0 {
+		remoteHost, arg = arg[:i], arg[i+1:]
+	}
+	remotePort, err := strconv.Atoi(arg)
+	if err != nil || remotePort <= 0 || remotePort > 65535 {
+		return portMapping{}, errors.New("invalid port number: " + arg)
+	}
+	return portMapping{
+		Protocol:   protocolTCP,
+		RemoteHost: remoteHost,
+		RemotePort: uint16(remotePort),
+	}, nil
+}
+
 // NewPortForwardCmd returns a new PortForwardCmd
 func NewPortForwardCmd(cmd *cobra.Command, args []string) (*PortForwardCmd, error) {
 	server := viper.GetString(argRootServer)
@@ -167,45 +461,204 @@ func NewPortForwardCmd(cmd *cobra.Command, args []string) (*PortForwardCmd, erro
 		return nil, err
 	}
 
-	portMappings, err := getPortMappings(args[1:])
+	stdio, err := cmd.Flags().GetBool(argStdio)
+	if err != nil {
+		return nil, err
+	}
+
+	socks5Port, err := cmd.Flags().GetUint16(argSOCKS5)
 	if err != nil {
 		return nil, err
 	}
 
+	status, err := cmd.Flags().GetBool(argStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	statsAddr, err := cmd.Flags().GetString(argStatsAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := cmd.Flags().GetString(argProfile)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDuration, err := cmd.Flags().GetDuration(argMaxDuration)
+	if err != nil {
+		return nil, err
+	} else if maxDuration < 0 {
+		return nil, errors.Errorf("invalid --%s: %s", argMaxDuration, maxDuration)
+	}
+
+	idleTimeout, err := cmd.Flags().GetDuration(argIdleTimeout)
+	if err != nil {
+		return nil, err
+	} else if idleTimeout < 0 {
+		return nil, errors.Errorf("invalid --%s: %s", argIdleTimeout, idleTimeout)
+	}
+
 	token, err := getAuthToken(cmd)
 	if err != nil {
 		return nil, err
 	}
 
-	return &PortForwardCmd{
-		server:       server,
-		token:        token,
-		skipVerify:   skipVerify,
-		deviceID:     args[0],
-		bindingHost:  bindingHost,
-		portMappings: portMappings,
-		recvChans:    make(map[string]chan *ws.ProtoMsg),
-		stop:         make(chan struct{}),
-	}, nil
+	c := &PortForwardCmd{
+		server:      server,
+		token:       token,
+		skipVerify:  skipVerify,
+		maxDuration: maxDuration,
+		idleTimeout: idleTimeout,
+		status:      status,
+		statsAddr:   statsAddr,
+	}
+
+	if profile != "" {
+		if len(args) > 0 || stdio || socks5Port > 0 {
+			return nil, errors.Errorf(
+				"the device, the port mappings, --%s and --%s can't be used with --%s",
+				argStdio, argSOCKS5, argProfile)
+		}
+		tunnels, err := getPortForwardProfile(viper.GetViper(), profile)
+		if err != nil {
+			return nil, err
+		}
+		for _, tunnel := range tunnels {
+			portMappings, err := getPortMappings(tunnel.Mappings)
+			if err != nil {
+				return nil, errors.Wrapf(err, "profile %s, device %s", profile, tunnel.Device)
+			}
+			tunnelBindingHost := bindingHost
+			if tunnel.Bind != "" {
+				tunnelBindingHost = tunnel.Bind
+			}
+			c.tunnels = append(c.tunnels, newPortForwardTunnel(c, tunnel.Device,
+				tunnelBindingHost, portMappings, false, tunnel.SOCKS5))
+		}
+	} else {
+		if len(args) == 0 {
+			return nil, errors.New("No device specified")
+		}
+		var portMappings []portMapping
+		if stdio {
+			if len(args) != 2 {
+				return nil, errors.New("a single remote port is required with --stdio")
+			}
+			mapping, err := getStdioPortMapping(args[1])
+			if err != nil {
+				return nil, err
+			}
+			portMappings = []portMapping{mapping}
+		} else {
+			portMappings, err = getPortMappings(args[1:])
+			if err != nil {
+				return nil, err
+			}
+			if len(portMappings) == 0 && socks5Port == 0 {
+				return nil, errors.New("No port mappings specified")
+			}
+		}
+		c.tunnels = []*portForwardTunnel{
+			newPortForwardTunnel(c, args[0], bindingHost, portMappings, stdio, socks5Port),
+		}
+	}
+
+	for _, tunnel := range c.tunnels {
+		c.stats = append(c.stats, tunnel.stats...)
+	}
+	return c, nil
 }
 
 // Run executes the command
 func (c *PortForwardCmd) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if c.statsAddr != "" {
+		if err := c.serveStats(ctx, c.statsAddr); err != nil {
+			return err
+		}
+	}
+	statusDone := make(chan struct{})
+	if c.status {
+		go func() {
+			c.showStatus(ctx)
+			close(statusDone)
+		}()
+	}
+
+	var err error
+	if len(c.tunnels) == 1 {
+		err = c.tunnels[0].Run()
+	} else {
+		err = c.runTunnels()
+	}
+
+	if c.status {
+		cancel()
+		<-statusDone
+		c.printStats(os.Stderr)
+	}
+	return err
+}
+
+// runTunnels runs all the tunnels concurrently, until all of them stop
+func (c *PortForwardCmd) runTunnels() error {
+	var wg sync.WaitGroup
+	var failed atomic.Int32
+	for _, tunnel := range c.tunnels {
+		wg.Add(1)
+		go func(tunnel *portForwardTunnel) {
+			defer wg.Done()
+			if err := tunnel.Run(); err != nil {
+				failed.Add(1)
+				fmt.Fprintf(os.Stderr, "error: device %s: %v\n", tunnel.deviceID, err.Error())
+			}
+		}(tunnel)
+	}
+	wg.Wait()
+	if n := failed.Load(); n > 0 {
+		return errors.Errorf("%d of %d tunnels failed", n, len(c.tunnels))
+	}
+	return nil
+}
+
+// Run forwards the port mappings of the device, restarting the port
+// forwarding if the connection is lost
+func (t *portForwardTunnel) Run() error {
+	// the maximum duration includes the restarts
+	var deadline time.Time
+	if t.cmd.maxDuration > 0 {
+		deadline = time.Now().Add(t.cmd.maxDuration)
+	}
 	for {
-		if err := c.run(); err != errRestart {
+		if err := t.run(deadline); err != errRestart {
 			return err
 		}
 	}
 }
 
-func (c *PortForwardCmd) run() error {
+// activity returns the number of TCP connections open and the number of
+// bytes forwarded so far
+func (t *portForwardTunnel) activity() (int64, int64) {
+	var active, bytes int64
+	for _, s := range t.stats {
+		active += s.active.Load()
+		bytes += s.bytesSent.Load() + s.bytesReceived.Load()
+	}
+	return active, bytes
+}
+
+func (t *portForwardTunnel) run(deadline time.Time) error {
 	ctx, cancelContext := context.WithCancel(context.Background())
 	defer cancelContext()
 
-	client := deviceconnect.NewClient(c.server, c.token, c.skipVerify)
+	client := deviceconnect.NewClient(t.cmd.server, t.cmd.token, t.cmd.skipVerify)
 
 	// check if the device is connected
-	device, err := client.GetDevice(c.deviceID)
+	device, err := client.GetDevice(t.deviceID)
 	if err != nil {
 		return errors.Wrap(err, "unable to get the device")
 	} else if device.Status != deviceconnect.CONNECTED {
@@ -213,7 +666,7 @@ func (c *PortForwardCmd) run() error {
 	}
 
 	// connect to the websocket and start the ping-pong connection health-check
-	err = client.Connect(c.deviceID, c.token)
+	err = client.Connect(t.deviceID, t.cmd.token)
 	if err != nil {
 		return err
 	}
@@ -222,7 +675,7 @@ func (c *PortForwardCmd) run() error {
 	defer client.Close()
 
 	// perform ws protocol handshake
-	err = c.handshake(client)
+	err = t.handshake(client)
 	if err != nil {
 		return err
 	}
@@ -230,54 +683,109 @@ func (c *PortForwardCmd) run() error {
 	// message channel
 	msgChan := make(chan *ws.ProtoMsg)
 
-	// start the local TCP listeners
-	for _, portMapping := range c.portMappings {
-		switch portMapping.Protocol {
-		case protocolTCP:
-			forwarder, err := NewTCPPortForwarder(c.bindingHost, portMapping.LocalPort,
-				portMapping.RemoteHost, portMapping.RemotePort)
-			if err != nil {
-				return err
+	// closed when the standard input and output are no longer forwarded
+	stdioDone := make(chan struct{})
+
+	// start the local TCP listeners, or forward the standard input and output
+	if t.stdio {
+		portMapping := t.portMappings[0]
+		forwarder := NewStdioPortForwarder(portMapping.RemoteHost, portMapping.RemotePort,
+			t.stats[0])
+		go func() {
+			forwarder.RunStdio(ctx, t.sessionID, msgChan, t.recvChans)
+			close(stdioDone)
+		}()
+	} else {
+		for i, portMapping := range t.portMappings {
+			switch portMapping.Protocol {
+			case protocolTCP:
+				forwarder, err := NewTCPPortForwarder(t.bindingHost, portMapping.LocalPort,
+					portMapping.RemoteHost, portMapping.RemotePort, t.stats[i])
+				if err != nil {
+					return err
+				}
+				go forwarder.Run(ctx, t.sessionID, msgChan, t.recvChans)
+			case protocolUDP:
+				forwarder, err := NewUDPPortForwarder(t.bindingHost, portMapping.LocalPort,
+					portMapping.RemoteHost, portMapping.RemotePort, t.stats[i])
+				if err != nil {
+					return err
+				}
+				go forwarder.Run(ctx, t.sessionID, msgChan, t.recvChans)
+			case protocolUnix:
+				forwarder, err := NewUnixPortForwarder(portMapping.LocalPath,
+					portMapping.RemoteHost, portMapping.RemotePort, t.stats[i])
+				if err != nil {
+					return err
+				}
+				go forwarder.Run(ctx, t.sessionID, msgChan, t.recvChans)
+			default:
+				return errors.New("unknown protocol: " + portMapping.Protocol)
 			}
-			go forwarder.Run(ctx, c.sessionID, msgChan, c.recvChans)
-		case protocolUDP:
-			forwarder, err := NewUDPPortForwarder(c.bindingHost, portMapping.LocalPort,
-				portMapping.RemoteHost, portMapping.RemotePort)
+		}
+		if t.socks5Port > 0 {
+			forwarder, err := NewSOCKS5PortForwarder(t.bindingHost, t.socks5Port,
+				t.stats[len(t.portMappings)])
 			if err != nil {
 				return err
 			}
-			go forwarder.Run(ctx, c.sessionID, msgChan, c.recvChans)
-		default:
-			return errors.New("unknown protocol: " + portMapping.Protocol)
+			go forwarder.Run(ctx, t.sessionID, msgChan, t.recvChans)
 		}
 	}
 
-	c.running = true
-	go c.processIncomingMessages(msgChan, client)
+	r := newPortForwardRun()
+	go t.processIncomingMessages(ctx, r, msgChan, client)
 
 	// handle CTRL+C and signals
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, unix.SIGINT, unix.SIGTERM)
+	defer signal.Stop(quit)
+
+	// the max duration and idle timeouts are disabled when zero
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	var idleCheck <-chan time.Time
+	if t.cmd.idleTimeout > 0 {
+		ticker := time.NewTicker(portForwardIdleCheckInterval)
+		defer ticker.Stop()
+		idleCheck = ticker.C
+	}
+	lastActive := time.Now()
+	_, lastBytes := t.activity()
 
 	// wait for CTRL+C, signals or stop
 	restart := false
-	timeout := time.Now().Add(portForwardMaxDuration)
-	for c.running {
+	for r.running.Load() {
 		select {
 		case msg := <-msgChan:
 			err := client.WriteMessage(msg)
 			if err != nil {
-				c.err = err
+				r.setErr(err)
 				break
 			}
-		case <-time.After(time.Until(timeout)):
-			c.err = errors.New("port forward timed out: max duration reached")
-			c.running = false
+		case <-timeout:
+			r.setErr(errors.New("port forward timed out: max duration reached"))
+			r.running.Store(false)
+		case now := <-idleCheck:
+			active, bytes := t.activity()
+			if active > 0 || bytes != lastBytes {
+				lastActive, lastBytes = now, bytes
+			} else if now.Sub(lastActive) >= t.cmd.idleTimeout {
+				fmt.Fprintf(os.Stderr, "Stopping the port forwarding to %s: idle timeout reached\n",
+					t.deviceID)
+				r.running.Store(false)
+			}
 		case <-quit:
-			c.running = false
-		case <-c.stop:
+			r.running.Store(false)
+		case <-stdioDone:
+			r.running.Store(false)
+		case <-r.stop:
 			restart = true
-			c.running = false
+			r.running.Store(false)
 		}
 	}
 
@@ -285,27 +793,24 @@ func (c *PortForwardCmd) run() error {
 	cancelContext()
 
 	// close the ws session
-	err = c.closeSession(client)
-	if c.err == nil && err != nil {
-		c.err = err
+	err = t.closeSession(client)
+	if r.Err() == nil && err != nil {
+		r.setErr(err)
 	}
 
-	// if stopping because of an error, restart the port-forwarding command
-	if restart {
+	// if stopping because of an error, restart the port-forwarding command;
+	// the standard input and output can't be forwarded again
+	if restart && !t.stdio {
 		return errRestart
 	}
 
 	// return the error message (if any)
-	return c.err
-}
-
-func (c *PortForwardCmd) Stop() {
-	c.stop <- struct{}{}
+	return r.Err()
 }
 
 // handshake initiates a handshake and checks that the device
 // is willing to accept port forward requests.
-func (c *PortForwardCmd) handshake(client *deviceconnect.Client) error {
+func (t *portForwardTunnel) handshake(client *deviceconnect.Client) error {
 	// open the session
 	body, err := msgpack.Marshal(&ws.Open{
 		Versions: []int{ws.ProtocolVersion},
@@ -354,12 +859,12 @@ func (c *PortForwardCmd) handshake(client *deviceconnect.Client) error {
 		return errPortForwardNotImplemented
 	}
 
-	c.sessionID = msg.Header.SessionID
+	t.sessionID = msg.Header.SessionID
 	return nil
 }
 
 // closeSession closes the WS session
-func (c *PortForwardCmd) closeSession(client *deviceconnect.Client) error {
+func (t *portForwardTunnel) closeSession(client *deviceconnect.Client) error {
 	m := &ws.ProtoMsg{
 		Header: ws.ProtoHdr{
 			Proto:   ws.ProtoTypeControl,
@@ -374,36 +879,42 @@ func (c *PortForwardCmd) closeSession(client *deviceconnect.Client) error {
 	return nil
 }
 
-func (c *PortForwardCmd) processIncomingMessages(
+func (t *portForwardTunnel) processIncomingMessages(
+	ctx context.Context,
+	r *portForwardRun,
 	msgChan chan *ws.ProtoMsg,
 	client *deviceconnect.Client,
 ) {
-	for c.running {
+	for r.running.Load() {
 		m, err := client.ReadMessage()
 		if err != nil {
-			c.err = err
-			c.Stop()
+			r.setErr(err)
+			r.Stop()
 			break
 		} else if m.Header.Proto == ws.ProtoTypeControl && m.Header.MsgType == ws.MessageTypePing {
 			m := &ws.ProtoMsg{
 				Header: ws.ProtoHdr{
 					Proto:     ws.ProtoTypeControl,
 					MsgType:   ws.MessageTypePong,
-					SessionID: c.sessionID,
+					SessionID: t.sessionID,
 				},
 			}
-			msgChan <- m
+			select {
+			case msgChan <- m:
+			case <-ctx.Done():
+				return
+			}
 		} else if m.Header.Proto == ws.ProtoTypePortForward &&
 			m.Header.MsgType == ws.MessageTypeError {
 			erro := new(ws.Error)
 			if err := msgpack.Unmarshal(m.Body, erro); err != nil &&
 				erro.MessageType != wspf.MessageTypePortForwardStop {
-				c.err = errors.New(fmt.Sprintf(
+				r.setErr(errors.New(fmt.Sprintf(
 					"Unable to start the port-forwarding: %s",
 					string(m.Body),
-				))
-				c.running = false
-				c.Stop()
+				)))
+				r.running.Store(false)
+				r.Stop()
 			}
 		} else if m.Header.Proto == ws.ProtoTypePortForward &&
 			(m.Header.MsgType == wspf.MessageTypePortForward ||
@@ -411,9 +922,7 @@ func (c *PortForwardCmd) processIncomingMessages(
 				m.Header.MsgType == wspf.MessageTypePortForwardStop) {
 			connectionID, _ := m.Header.Properties[wspf.PropertyConnectionID].(string)
 			if connectionID != "" {
-				if recvChan, ok := c.recvChans[connectionID]; ok {
-					recvChan <- m
-				}
+				t.recvChans.Send(connectionID, m)
 			}
 		}
 	}
@@ -0,0 +1,233 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mendersoftware/go-lib-micro/ws"
+	"github.com/pkg/errors"
+)
+
+const (
+	socks5Version = 0x05
+
+	socks5MethodNoAuth       = 0x00
+	socks5MethodNoAcceptable = 0xff
+
+	socks5CommandConnect = 0x01
+
+	socks5AddrIPv4   = 0x01
+	socks5AddrDomain = 0x03
+	socks5AddrIPv6   = 0x04
+
+	socks5ReplySucceeded           = 0x00
+	socks5ReplyCommandNotSupported = 0x07
+	socks5ReplyAddrNotSupported    = 0x08
+
+	// maximum time allowed for the SOCKS5 handshake
+	socks5HandshakeTimeout = 10 * time.Second
+)
+
+var errSOCKS5Handshake = errors.New("SOCKS5 handshake failed")
+
+// SOCKS5PortForwarder runs a local SOCKS5 server, forwarding each
+// connection to the host and port requested by the client, as seen from
+// the device
+type SOCKS5PortForwarder struct {
+	listen net.Listener
+	stats  *portForwardStats
+}
+
+// socks5Connection is a connection which completed the SOCKS5 handshake
+type socks5Connection struct {
+	conn       net.Conn
+	remoteHost string
+	remotePort uint16
+}
+
+func NewSOCKS5PortForwarder(
+	bindingHost string,
+	localPort uint16,
+	stats *portForwardStats,
+) (*SOCKS5PortForwarder, error) {
+	fmt.Fprintf(os.Stderr, "Forwarding from socks5://%s:%d\n", bindingHost, localPort)
+	listen, err := net.Listen(protocolTCP, bindingHost+":"+strconv.Itoa(int(localPort)))
+	if err != nil {
+		return nil, err
+	}
+	return &SOCKS5PortForwarder{
+		listen: listen,
+		stats:  stats,
+	}, nil
+}
+
+func (p *SOCKS5PortForwarder) Run(
+	ctx context.Context,
+	sessionID string,
+	msgChan chan *ws.ProtoMsg,
+	recvChans *recvChannels,
+) {
+	defer p.listen.Close()
+	acceptedConnections := make(chan socks5Connection)
+
+	// go-routine to accept new connections and perform the handshakes
+	go func() {
+		for {
+			conn, err := p.listen.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_ = conn.SetDeadline(time.Now().Add(socks5HandshakeTimeout))
+				remoteHost, remotePort, err := socks5Handshake(conn)
+				if err != nil {
+					p.stats.errors.Add(1)
+					fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+					conn.Close()
+					return
+				}
+				_ = conn.SetDeadline(time.Time{})
+				fmt.Fprintf(
+					os.Stderr,
+					"Handling connection from %s to %s:%d\n",
+					conn.RemoteAddr().String(),
+					remoteHost,
+					remotePort,
+				)
+				select {
+				case acceptedConnections <- socks5Connection{
+					conn:       conn,
+					remoteHost: remoteHost,
+					remotePort: remotePort,
+				}:
+				case <-ctx.Done():
+					conn.Close()
+				}
+			}()
+		}
+	}()
+
+	// handle new connections
+	for {
+		select {
+		case c := <-acceptedConnections:
+			forwarder := &TCPPortForwarder{
+				remoteHost: c.remoteHost,
+				remotePort: c.remotePort,
+				mutexAck:   newAckMutexes(),
+				stats:      p.stats,
+			}
+			connectionUUID, _ := uuid.NewUUID()
+			connectionID := connectionUUID.String()
+			go forwarder.handleRequest(ctx, c.conn, sessionID, connectionID, recvChans, msgChan)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// socks5Handshake performs the server side of the SOCKS5 handshake,
+// without authentication, and returns the host and port requested with
+// the CONNECT command; the connection is reported as established right
+// away, as the port forward protocol doesn't confirm it
+func socks5Handshake(conn io.ReadWriter) (string, uint16, error) {
+	// greeting: version, number of methods, methods
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", 0, errors.Wrap(err, errSOCKS5Handshake.Error())
+	}
+	if header[0] != socks5Version {
+		return "", 0, errors.Wrapf(errSOCKS5Handshake, "unsupported version %d", header[0])
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return "", 0, errors.Wrap(err, errSOCKS5Handshake.Error())
+	}
+	method := byte(socks5MethodNoAcceptable)
+	for _, m := range methods {
+		if m == socks5MethodNoAuth {
+			method = socks5MethodNoAuth
+			break
+		}
+	}
+	if _, err := conn.Write([]byte{socks5Version, method}); err != nil {
+		return "", 0, errors.Wrap(err, errSOCKS5Handshake.Error())
+	}
+	if method == socks5MethodNoAcceptable {
+		return "", 0, errors.Wrap(errSOCKS5Handshake, "authentication not supported")
+	}
+
+	// request: version, command, reserved, address type, address, port
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return "", 0, errors.Wrap(err, errSOCKS5Handshake.Error())
+	}
+	if request[0] != socks5Version {
+		return "", 0, errors.Wrapf(errSOCKS5Handshake, "unsupported version %d", request[0])
+	}
+	var host string
+	switch request[3] {
+	case socks5AddrIPv4, socks5AddrIPv6:
+		addr := make([]byte, net.IPv4len)
+		if request[3] == socks5AddrIPv6 {
+			addr = make([]byte, net.IPv6len)
+		}
+		if _, err := io.ReadFull(conn, addr); err != nil {
+			return "", 0, errors.Wrap(err, errSOCKS5Handshake.Error())
+		}
+		host = net.IP(addr).String()
+	case socks5AddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return "", 0, errors.Wrap(err, errSOCKS5Handshake.Error())
+		}
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return "", 0, errors.Wrap(err, errSOCKS5Handshake.Error())
+		}
+		host = string(domain)
+	default:
+		_ = socks5Reply(conn, socks5ReplyAddrNotSupported)
+		return "", 0, errors.Wrapf(errSOCKS5Handshake,
+			"unsupported address type %d", request[3])
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return "", 0, errors.Wrap(err, errSOCKS5Handshake.Error())
+	}
+	if request[1] != socks5CommandConnect {
+		_ = socks5Reply(conn, socks5ReplyCommandNotSupported)
+		return "", 0, errors.Wrapf(errSOCKS5Handshake, "unsupported command %d", request[1])
+	}
+	if err := socks5Reply(conn, socks5ReplySucceeded); err != nil {
+		return "", 0, errors.Wrap(err, errSOCKS5Handshake.Error())
+	}
+	return host, binary.BigEndian.Uint16(port), nil
+}
+
+// socks5Reply sends a reply to the request, with an unspecified bound
+// address
+func socks5Reply(w io.Writer, reply byte) error {
+	_, err := w.Write([]byte{socks5Version, reply, 0x00, socks5AddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestSOCKS5Handshake(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]struct {
+		request []byte
+		reply   []byte
+		host    string
+		port    uint16
+		err     error
+	}{
+		"ok, IPv4": {
+			request: []byte{5, 1, 0, 5, 1, 0, 1, 192, 168, 1, 10, 0, 80},
+			reply:   []byte{5, 0, 5, 0, 0, 1, 0, 0, 0, 0, 0, 0},
+			host:    "192.168.1.10",
+			port:    80,
+		},
+		"ok, domain": {
+			request: append(append([]byte{5, 2, 2, 0, 5, 1, 0, 3, 9}, "localhost"...), 0x1f, 0x90),
+			reply:   []byte{5, 0, 5, 0, 0, 1, 0, 0, 0, 0, 0, 0},
+			host:    "localhost",
+			port:    8080,
+		},
+		"error, authentication required": {
+			request: []byte{5, 1, 2},
+			reply:   []byte{5, 0xff},
+			err:     errSOCKS5Handshake,
+		},
+		"error, bind command": {
+			request: []byte{5, 1, 0, 5, 2, 0, 1, 127, 0, 0, 1, 0, 80},
+			reply:   []byte{5, 0, 5, 7, 0, 1, 0, 0, 0, 0, 0, 0},
+			err:     errSOCKS5Handshake,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var reply bytes.Buffer
+			conn := struct {
+				io.Reader
+				io.Writer
+			}{bytes.NewReader(tc.request), &reply}
+
+			host, port, err := socks5Handshake(conn)
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Unexpected error: %v, expected: %v", err, tc.err)
+			}
+			if host != tc.host || port != tc.port {
+				t.Errorf("Unexpected address: %s:%d", host, port)
+			}
+			if !bytes.Equal(reply.Bytes(), tc.reply) {
+				t.Errorf("Unexpected reply: %v", reply.Bytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+const (
+	// refresh interval of the live status line
+	portForwardStatusRefresh = time.Second
+	// interval of the summaries printed when the output isn't a terminal
+	portForwardStatsInterval = time.Minute
+
+	portForwardMetricsPath = "/metrics"
+)
+
+// portForwardStats holds the statistics of a port mapping; bytes sent are
+// read from the local connections and sent to the device, bytes received
+// are received from the device
+type portForwardStats struct {
+	device        string
+	mapping       string
+	connections   atomic.Int64
+	active        atomic.Int64
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+	errors        atomic.Int64
+
+	// udpPeer is the address of the last UDP client; it is kept across
+	// the restarts of the forwarding
+	udpPeerMutex sync.Mutex
+	udpPeer      string
+}
+
+func newPortForwardStats(device string, mapping string) *portForwardStats {
+	return &portForwardStats{
+		device:  device,
+		mapping: mapping,
+	}
+}
+
+// connectionOpened counts a new connection, returning the function to call
+// when it is closed
+func (s *portForwardStats) connectionOpened() func() {
+	s.connections.Add(1)
+	s.active.Add(1)
+	return func() {
+		s.active.Add(-1)
+	}
+}
+
+// udpPeerSeen counts a new connection when a datagram comes from another
+// UDP client than the previous one, as UDP has no connections; they are
+// never active, so they don't prevent the idle timeout
+func (s *portForwardStats) udpPeerSeen(addr string) {
+	s.udpPeerMutex.Lock()
+	defer s.udpPeerMutex.Unlock()
+	if addr != s.udpPeer {
+		s.udpPeer = addr
+		s.connections.Add(1)
+	}
+}
+
+func (s *portForwardStats) String() string {
+	return fmt.Sprintf("%s %s: %d active, %d connections, %s sent, %s received, %d errors",
+		s.device, s.mapping, s.active.Load(), s.connections.Load(), formatBytes(s.bytesSent.Load()),
+		formatBytes(s.bytesReceived.Load()), s.errors.Load())
+}
+
+func (m portMapping) String() string {
+	switch m.Protocol {
+	case protocolUnix:
+		return fmt.Sprintf("unix:%s:%s:%d", m.LocalPath, m.RemoteHost, m.RemotePort)
+	default:
+		return fmt.Sprintf("%s/%d:%s:%d", m.Protocol, m.LocalPort, m.RemoteHost, m.RemotePort)
+	}
+}
+
+// formatBytes formats a number of bytes with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// showStatus shows the statistics until the context is canceled: as a
+// live status line if the standard error is a terminal, or as a periodic
+// summary otherwise
+func (c *PortForwardCmd) showStatus(ctx context.Context) {
+	live := term.IsTerminal(int(os.Stderr.Fd()))
+	interval := portForwardStatsInterval
+	if live {
+		interval = portForwardStatusRefresh
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if live {
+				fmt.Fprint(os.Stderr, "\r\033[K"+c.statusLine())
+			} else {
+				c.printStats(os.Stderr)
+			}
+		case <-ctx.Done():
+			if live {
+				fmt.Fprint(os.Stderr, "\r\033[K")
+			}
+			return
+		}
+	}
+}
+
+// statusLine returns the statistics of all the port mappings together
+func (c *PortForwardCmd) statusLine() string {
+	var active, connections, sent, received, errs int64
+	for _, s := range c.stats {
+		active += s.active.Load()
+		connections += s.connections.Load()
+		sent += s.bytesSent.Load()
+		received += s.bytesReceived.Load()
+		errs += s.errors.Load()
+	}
+	return fmt.Sprintf("%d active, %d connections, %s sent, %s received, %d errors",
+		active, connections, formatBytes(sent), formatBytes(received), errs)
+}
+
+// printStats prints the statistics of each port mapping
+func (c *PortForwardCmd) printStats(w io.Writer) {
+	for _, s := range c.stats {
+		fmt.Fprintln(w, s.String())
+	}
+}
+
+// serveStats serves the statistics in the Prometheus text format on the
+// given local address, until the context is canceled
+func (c *PortForwardCmd) serveStats(ctx context.Context, addr string) error {
+	listen, err := net.Listen(protocolTCP, addr)
+	if err != nil {
+		return errors.Wrap(err, "Unable to serve the statistics")
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(portForwardMetricsPath, c.handleMetrics)
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	fmt.Fprintf(os.Stderr, "Serving the statistics on http://%s%s\n",
+		listen.Addr(), portForwardMetricsPath)
+	go func() {
+		_ = server.Serve(listen)
+	}()
+	return nil
+}
+
+func (c *PortForwardCmd) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetrics(w, c.stats)
+}
+
+// writeMetrics writes the statistics in the Prometheus text format
+func writeMetrics(w io.Writer, stats []*portForwardStats) {
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value func(*portForwardStats) int64
+	}{
+		{"mender_cli_port_forward_connections_total", "counter",
+			"Number of connections forwarded.",
+			func(s *portForwardStats) int64 { return s.connections.Load() }},
+		{"mender_cli_port_forward_connections_active", "gauge",
+			"Number of connections currently forwarded.",
+			func(s *portForwardStats) int64 { return s.active.Load() }},
+		{"mender_cli_port_forward_sent_bytes_total", "counter",
+			"Bytes sent to the device.",
+			func(s *portForwardStats) int64 { return s.bytesSent.Load() }},
+		{"mender_cli_port_forward_received_bytes_total", "counter",
+			"Bytes received from the device.",
+			func(s *portForwardStats) int64 { return s.bytesReceived.Load() }},
+		{"mender_cli_port_forward_errors_total", "counter",
+			"Number of errors while forwarding.",
+			func(s *portForwardStats) int64 { return s.errors.Load() }},
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for _, s := range stats {
+			fmt.Fprintf(w, "%s{device=\"%s\",mapping=\"%s\"} %d\n", m.name,
+				escaper.Replace(s.device), escaper.Replace(s.mapping), m.value(s))
+		}
+	}
+}
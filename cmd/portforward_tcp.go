@@ -37,7 +37,45 @@ type TCPPortForwarder struct {
 	listen     net.Listener
 	remoteHost string
 	remotePort uint16
-	mutexAck   map[string]*sync.Mutex
+	mutexAck   *ackMutexes
+	stats      *portForwardStats
+}
+
+// ackMutexes holds the mutexes of the connections which are locked while
+// waiting for the ack of the last message sent to the device; they are
+// accessed by the forwarders of the connections and their receivers
+type ackMutexes struct {
+	mutex   sync.Mutex
+	mutexes map[string]*sync.Mutex
+}
+
+func newAckMutexes() *ackMutexes {
+	return &ackMutexes{
+		mutexes: map[string]*sync.Mutex{},
+	}
+}
+
+// Add registers a new connection, returning its mutex
+func (a *ackMutexes) Add(connectionID string) *sync.Mutex {
+	m := &sync.Mutex{}
+	a.mutex.Lock()
+	a.mutexes[connectionID] = m
+	a.mutex.Unlock()
+	return m
+}
+
+// Remove unregisters a connection
+func (a *ackMutexes) Remove(connectionID string) {
+	a.mutex.Lock()
+	delete(a.mutexes, connectionID)
+	a.mutex.Unlock()
+}
+
+// Get returns the mutex of a connection, or nil if not registered
+func (a *ackMutexes) Get(connectionID string) *sync.Mutex {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.mutexes[connectionID]
 }
 
 func NewTCPPortForwarder(
@@ -45,8 +83,10 @@ func NewTCPPortForwarder(
 	localPort uint16,
 	remoteHost string,
 	remotePort uint16,
+	stats *portForwardStats,
 ) (*TCPPortForwarder, error) {
-	fmt.Printf("Forwarding from %s:%d -> %s:%d\n", bindingHost, localPort, remoteHost, remotePort)
+	fmt.Fprintf(os.Stderr, "Forwarding from %s:%d -> %s:%d\n",
+		bindingHost, localPort, remoteHost, remotePort)
 	listen, err := net.Listen(protocolTCP, bindingHost+":"+strconv.Itoa(int(localPort)))
 	if err != nil {
 		return nil, err
@@ -55,15 +95,56 @@ func NewTCPPortForwarder(
 		listen:     listen,
 		remoteHost: remoteHost,
 		remotePort: remotePort,
-		mutexAck:   map[string]*sync.Mutex{},
+		mutexAck:   newAckMutexes(),
+		stats:      stats,
 	}, nil
 }
 
+// NewStdioPortForwarder returns a TCPPortForwarder which forwards the
+// standard input and output, instead of the connections to a local port
+func NewStdioPortForwarder(
+	remoteHost string,
+	remotePort uint16,
+	stats *portForwardStats,
+) *TCPPortForwarder {
+	return &TCPPortForwarder{
+		remoteHost: remoteHost,
+		remotePort: remotePort,
+		mutexAck:   newAckMutexes(),
+		stats:      stats,
+	}
+}
+
+// stdioConn is a connection reading from the standard input and writing
+// to the standard output
+type stdioConn struct {
+	io.Reader
+	io.Writer
+}
+
+func (stdioConn) Close() error {
+	return nil
+}
+
+// RunStdio forwards the standard input and output until the connection is
+// closed by either side
+func (p *TCPPortForwarder) RunStdio(
+	ctx context.Context,
+	sessionID string,
+	msgChan chan *ws.ProtoMsg,
+	recvChans *recvChannels,
+) {
+	connectionUUID, _ := uuid.NewUUID()
+	connectionID := connectionUUID.String()
+	conn := stdioConn{Reader: os.Stdin, Writer: os.Stdout}
+	p.handleRequest(ctx, conn, sessionID, connectionID, recvChans, msgChan)
+}
+
 func (p *TCPPortForwarder) Run(
 	ctx context.Context,
 	sessionID string,
 	msgChan chan *ws.ProtoMsg,
-	recvChans map[string]chan *ws.ProtoMsg,
+	recvChans *recvChannels,
 ) {
 	// listen for new connections
 	defer p.listen.Close()
@@ -76,9 +157,15 @@ func (p *TCPPortForwarder) Run(
 			if err != nil {
 				return
 			}
-			fmt.Printf(
+			// the clients of Unix sockets are usually unnamed
+			from := conn.RemoteAddr().String()
+			if from == "" {
+				from = conn.RemoteAddr().Network()
+			}
+			fmt.Fprintf(
+				os.Stderr,
 				"Handling connection from %s to %s\n",
-				conn.RemoteAddr().String(),
+				from,
 				conn.LocalAddr().String(),
 			)
 			acceptedConnections <- conn
@@ -91,9 +178,7 @@ func (p *TCPPortForwarder) Run(
 		case conn := <-acceptedConnections:
 			connectionUUID, _ := uuid.NewUUID()
 			connectionID := connectionUUID.String()
-			recvChan := make(chan *ws.ProtoMsg, portForwardTCPChannelSize)
-			recvChans[connectionID] = recvChan
-			go p.handleRequest(ctx, conn, sessionID, connectionID, recvChan, msgChan)
+			go p.handleRequest(ctx, conn, sessionID, connectionID, recvChans, msgChan)
 		case <-ctx.Done():
 			return
 		}
@@ -102,21 +187,24 @@ func (p *TCPPortForwarder) Run(
 
 func (p *TCPPortForwarder) handleRequest(
 	ctx context.Context,
-	conn net.Conn,
+	conn io.ReadWriteCloser,
 	sessionID string,
 	connectionID string,
-	recvChan chan *ws.ProtoMsg,
+	recvChans *recvChannels,
 	msgChan chan *ws.ProtoMsg,
 ) {
 	defer conn.Close()
 
-	p.mutexAck[connectionID] = &sync.Mutex{}
-	defer func() {
-		delete(p.mutexAck, connectionID)
-	}()
+	recvChan := recvChans.Add(connectionID, portForwardTCPChannelSize)
+	defer recvChans.Remove(connectionID)
+	defer p.stats.connectionOpened()()
+
+	mutexAck := p.mutexAck.Add(connectionID)
+	defer p.mutexAck.Remove(connectionID)
 
 	errChan := make(chan error)
 	dataChan := make(chan []byte)
+	remoteStopped := make(chan struct{})
 
 	protocol := portforward.PortForwardProtocol(wspf.PortForwardProtocolTCP)
 	portforwardNew := &wspf.PortForwardNew{
@@ -171,15 +259,18 @@ func (p *TCPPortForwarder) handleRequest(
 				if m.Header.Proto == ws.ProtoTypePortForward &&
 					m.Header.MsgType == wspf.MessageTypePortForwardStop {
 					sendStopMessage = false
+					close(remoteStopped)
 					return
 				} else if m.Header.Proto == ws.ProtoTypePortForward &&
 					m.Header.MsgType == wspf.MessageTypePortForward {
 					_, err := conn.Write(m.Body)
 					if err != nil {
 						if errors.Unwrap(err) != net.ErrClosed {
+							p.stats.errors.Add(1)
 							fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
 						}
 					} else {
+						p.stats.bytesReceived.Add(int64(len(m.Body)))
 						// send the ack
 						m := &ws.ProtoMsg{
 							Header: ws.ProtoHdr{
@@ -195,7 +286,7 @@ func (p *TCPPortForwarder) handleRequest(
 					}
 				} else if m.Header.Proto == ws.ProtoTypePortForward &&
 					m.Header.MsgType == wspf.MessageTypePortForwardAck {
-					if m, ok := p.mutexAck[connectionID]; ok {
+					if m := p.mutexAck.Get(connectionID); m != nil {
 						m.Unlock()
 					}
 				}
@@ -210,12 +301,15 @@ func (p *TCPPortForwarder) handleRequest(
 		select {
 		case err := <-errChan:
 			if err != io.EOF {
+				p.stats.errors.Add(1)
 				fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
 			}
 			return
 		case data := <-dataChan:
+			p.stats.bytesSent.Add(int64(len(data)))
+
 			// lock the ack mutex, we don't allow more than one in-flight message
-			p.mutexAck[connectionID].Lock()
+			mutexAck.Lock()
 
 			m := &ws.ProtoMsg{
 				Header: ws.ProtoHdr{
@@ -229,6 +323,8 @@ func (p *TCPPortForwarder) handleRequest(
 				Body: data,
 			}
 			msgChan <- m
+		case <-remoteStopped:
+			return
 		case <-ctx.Done():
 			return
 		}
@@ -238,7 +334,7 @@ func (p *TCPPortForwarder) handleRequest(
 func (p *TCPPortForwarder) handleRequestConnection(
 	dataChan chan []byte,
 	errChan chan error,
-	conn net.Conn,
+	conn io.Reader,
 ) {
 	data := make([]byte, readBuffLength)
 
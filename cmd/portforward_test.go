@@ -0,0 +1,192 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mendersoftware/go-lib-micro/ws"
+	"github.com/spf13/viper"
+)
+
+func TestGetPortMappings(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]struct {
+		args     []string
+		mappings []portMapping
+		err      bool
+	}{
+		"ok, tcp and udp": {
+			args: []string{"8000", "udp/53:10.0.0.1:5353"},
+			mappings: []portMapping{
+				{Protocol: protocolTCP, LocalPort: 8000, RemoteHost: localhost, RemotePort: 8000},
+				{Protocol: protocolUDP, LocalPort: 53, RemoteHost: "10.0.0.1", RemotePort: 5353},
+			},
+		},
+		"ok, unix": {
+			args: []string{"unix:/tmp/docker.sock:2375", "unix:/tmp/db.sock:10.0.0.1:5432"},
+			mappings: []portMapping{
+				{Protocol: protocolUnix, LocalPath: "/tmp/docker.sock",
+					RemoteHost: localhost, RemotePort: 2375},
+				{Protocol: protocolUnix, LocalPath: "/tmp/db.sock",
+					RemoteHost: "10.0.0.1", RemotePort: 5432},
+			},
+		},
+		"error, unix without remote port": {
+			args: []string{"unix:/tmp/docker.sock"},
+			err:  true,
+		},
+		"error, unix with invalid remote port": {
+			args: []string{"unix:/tmp/docker.sock:docker"},
+			err:  true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mappings, err := getPortMappings(tc.args)
+			if tc.err {
+				if err == nil {
+					t.Errorf("Expected an error, got %+v", mappings)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %s", err.Error())
+			}
+			if !reflect.DeepEqual(mappings, tc.mappings) {
+				t.Errorf("Unexpected port mappings: %+v", mappings)
+			}
+		})
+	}
+}
+
+func TestUDPPeerSeen(t *testing.T) {
+	t.Parallel()
+	stats := newPortForwardStats("1234", "udp/5353:127.0.0.1:53")
+	for _, addr := range []string{"127.0.0.1:40000", "127.0.0.1:40000", "127.0.0.1:40001"} {
+		stats.udpPeerSeen(addr)
+	}
+	if n := stats.connections.Load(); n != 2 {
+		t.Errorf("Expected 2 connections, got %d", n)
+	}
+	if n := stats.active.Load(); n != 0 {
+		t.Errorf("Expected no active connections, got %d", n)
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	t.Parallel()
+	stats := newPortForwardStats("1234", "tcp/8000:127.0.0.1:8000")
+	closed := stats.connectionOpened()
+	stats.connectionOpened()
+	closed()
+	stats.bytesSent.Add(1024)
+	stats.bytesReceived.Add(2048)
+
+	var buf bytes.Buffer
+	writeMetrics(&buf, []*portForwardStats{stats})
+	labels := `{device="1234",mapping="tcp/8000:127.0.0.1:8000"}`
+	for _, line := range []string{
+		"# TYPE mender_cli_port_forward_connections_total counter\n",
+		"mender_cli_port_forward_connections_total" + labels + " 2\n",
+		"mender_cli_port_forward_connections_active" + labels + " 1\n",
+		"mender_cli_port_forward_sent_bytes_total" + labels + " 1024\n",
+		"mender_cli_port_forward_received_bytes_total" + labels + " 2048\n",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("Missing metric %q in:\n%s", line, buf.String())
+		}
+	}
+	if s := stats.String(); s != "1234 tcp/8000:127.0.0.1:8000: 1 active, 2 connections, "+
+		"1.0 KiB sent, 2.0 KiB received, 0 errors" {
+		t.Errorf("Unexpected summary: %s", s)
+	}
+}
+
+func TestGetPortForwardProfile(t *testing.T) {
+	t.Parallel()
+	config := viper.New()
+	config.SetConfigType("json")
+	err := config.ReadConfig(strings.NewReader(`{
+		"port-forward-profiles": {
+			"Web": [
+				{"device": "1234", "mappings": ["8080:80", "udp/5353"]},
+				{"device": "5678", "bind": "0.0.0.0", "socks5": 1080}
+			],
+			"empty": [{"device": "1234"}]
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	tunnels, err := getPortForwardProfile(config, "web")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	expected := []portForwardProfileTunnel{
+		{Device: "1234", Mappings: []string{"8080:80", "udp/5353"}},
+		{Device: "5678", Bind: "0.0.0.0", SOCKS5: 1080},
+	}
+	if !reflect.DeepEqual(tunnels, expected) {
+		t.Errorf("Unexpected tunnels: %+v", tunnels)
+	}
+
+	if _, err := getPortForwardProfile(config, "empty"); err == nil {
+		t.Error("Expected an error for a tunnel without port mappings")
+	}
+	if _, err := getPortForwardProfile(config, "missing"); err == nil {
+		t.Error("Expected an error for a missing profile")
+	}
+}
+
+func TestRecvChannels(t *testing.T) {
+	t.Parallel()
+	recvChans := newRecvChannels()
+	recvChan := recvChans.Add("1234", 1)
+
+	m := &ws.ProtoMsg{}
+	recvChans.Send("1234", m)
+	if received := <-recvChan; received != m {
+		t.Errorf("Unexpected message: %+v", received)
+	}
+
+	// sending to a removed or unknown connection doesn't block
+	recvChans.Send("1234", m)
+	recvChans.Remove("1234")
+	recvChans.Send("1234", m)
+	recvChans.Send("5678", m)
+	recvChans.Remove("5678")
+}
+
+func TestPortForwardRunStop(t *testing.T) {
+	t.Parallel()
+	r := newPortForwardRun()
+	if !r.running.Load() {
+		t.Error("Expected the run to be running")
+	}
+
+	// stopping more than once, with nobody waiting, doesn't block
+	r.Stop()
+	r.Stop()
+	select {
+	case <-r.stop:
+	default:
+		t.Error("Expected the stop channel to be closed")
+	}
+}
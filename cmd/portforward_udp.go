@@ -38,6 +38,7 @@ type UDPPortForwarder struct {
 	remotePort    uint16
 	sourceAddr    *net.UDPAddr
 	waitGroupAcks *sync.WaitGroup
+	stats         *portForwardStats
 }
 
 func NewUDPPortForwarder(
@@ -45,8 +46,10 @@ func NewUDPPortForwarder(
 	localPort uint16,
 	remoteHost string,
 	remotePort uint16,
+	stats *portForwardStats,
 ) (*UDPPortForwarder, error) {
-	fmt.Printf(
+	fmt.Fprintf(
+		os.Stderr,
 		"Forwarding from udp/%s:%d -> udp/%s:%d\n",
 		bindingHost,
 		localPort,
@@ -66,6 +69,7 @@ func NewUDPPortForwarder(
 		remoteHost:    remoteHost,
 		remotePort:    remotePort,
 		waitGroupAcks: &sync.WaitGroup{},
+		stats:         stats,
 	}, nil
 }
 
@@ -73,15 +77,15 @@ func (p *UDPPortForwarder) Run(
 	ctx context.Context,
 	sessionID string,
 	msgChan chan *ws.ProtoMsg,
-	recvChans map[string]chan *ws.ProtoMsg,
+	recvChans *recvChannels,
 ) {
 	// listen for new connections
 	defer p.conn.Close()
 
 	connectionUUID, _ := uuid.NewUUID()
 	connectionID := connectionUUID.String()
-	recvChan := make(chan *ws.ProtoMsg, portForwardUDPChannelSize)
-	recvChans[connectionID] = recvChan
+	recvChan := recvChans.Add(connectionID, portForwardUDPChannelSize)
+	defer recvChans.Remove(connectionID)
 
 	protocol := portforward.PortForwardProtocol(wspf.PortForwardProtocolUDP)
 	portforwardNew := &wspf.PortForwardNew{
@@ -143,8 +147,10 @@ func (p *UDPPortForwarder) Run(
 					m.Header.MsgType == wspf.MessageTypePortForward {
 					_, err := p.conn.WriteToUDP(m.Body, p.sourceAddr)
 					if err != nil {
+						p.stats.errors.Add(1)
 						fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
 					} else {
+						p.stats.bytesReceived.Add(int64(len(m.Body)))
 						// send the ack
 						m := &ws.ProtoMsg{
 							Header: ws.ProtoHdr{
@@ -173,10 +179,13 @@ func (p *UDPPortForwarder) Run(
 		select {
 		case err := <-errChan:
 			if err != io.EOF {
+				p.stats.errors.Add(1)
 				fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
 			}
 			return
 		case data := <-dataChan:
+			p.stats.bytesSent.Add(int64(len(data)))
+
 			// wait to receive all the previous acks
 			p.waitGroupAcks.Wait()
 
@@ -211,6 +220,7 @@ func (p *UDPPortForwarder) handleRequestConnection(dataChan chan []byte, errChan
 			break
 		}
 		if n > 0 {
+			p.stats.udpPeerSeen(udpAddr.String())
 			p.sourceAddr = udpAddr
 			tmp := make([]byte, n)
 			copy(tmp, data[:n])
@@ -0,0 +1,43 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+// NewUnixPortForwarder returns a TCPPortForwarder listening on a local Unix
+// socket instead of a TCP port; the socket is only accessible by the
+// current user, the connections from other users are rejected, and it is
+// removed when the forwarder stops
+func NewUnixPortForwarder(
+	localPath string,
+	remoteHost string,
+	remotePort uint16,
+	stats *portForwardStats,
+) (*TCPPortForwarder, error) {
+	fmt.Fprintf(os.Stderr, "Forwarding from unix:%s -> %s:%d\n", localPath, remoteHost, remotePort)
+	listen, err := listenUnixPrivate(localPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &TCPPortForwarder{
+		listen:     listen,
+		remoteHost: remoteHost,
+		remotePort: remotePort,
+		mutexAck:   newAckMutexes(),
+		stats:      stats,
+	}, nil
+}
@@ -0,0 +1,416 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mendersoftware/mender-cli/client/deployments"
+	"github.com/mendersoftware/mender-cli/log"
+)
+
+const (
+	argReleaseName  = "name"
+	argReleaseTag   = "tag"
+	argReleaseNotes = "file"
+)
+
+var releasesCmd = &cobra.Command{
+	Use:   "releases",
+	Short: "Operations on the releases of the Mender server.",
+	Long: "Operations on the releases of the Mender server. A release groups\n" +
+		"the artifacts with the same name, and has tags and notes.",
+}
+
+var releasesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Get a list of releases from the Mender server.",
+	Args:  cobra.NoArgs,
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewReleasesListCmd(c, args)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+var releasesShowCmd = &cobra.Command{
+	Use:   "show [flags] RELEASE",
+	Short: "Show a release, its tags, notes and artifacts.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewReleasesShowCmd(c, args)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+var releasesTagCmd = &cobra.Command{
+	Use:   "tag RELEASE [TAG...]",
+	Short: "Set the tags of a release.",
+	Long: "Replace the tags of a release with the given ones. Without tags,\n" +
+		"all the tags of the release are removed.",
+	Args: cobra.MinimumNArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewReleasesTagCmd(c, args)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+var releasesNotesCmd = &cobra.Command{
+	Use:   "notes [flags] RELEASE",
+	Short: "Show or edit the notes of a release.",
+	Long: "Show the notes of a release or, with --file, replace them with the\n" +
+		"content of a file ('-' reads the notes from the standard input).",
+	Args: cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewReleasesNotesCmd(c, args)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+var releasesDeleteCmd = &cobra.Command{
+	Use:   "delete RELEASE...",
+	Short: "Delete releases and all their artifacts from the Mender server.",
+	Long: "Delete releases and all their artifacts from the Mender server. The\n" +
+		"releases used by active deployments can't be deleted.",
+	Args: cobra.MinimumNArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewReleasesDeleteCmd(c, args)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+func init() {
+	releasesListCmd.Flags().IntP(argPerPage, "N", 20, "Number of results to display")
+	releasesListCmd.Flags().IntP(argPage, "P", 1, "Page number to return")
+	releasesListCmd.Flags().StringP(argReleaseName, "", "",
+		"list the releases whose name starts with this prefix")
+	releasesListCmd.Flags().StringP(argReleaseTag, "", "", "list the releases with this tag")
+	releasesListCmd.Flags().BoolP(argRawMode, "r", false, "releases list raw mode (json)")
+
+	releasesShowCmd.Flags().BoolP(argRawMode, "r", false, "release show raw mode (json)")
+
+	releasesNotesCmd.Flags().StringP(argReleaseNotes, "f", "",
+		"replace the notes with the content of this file ('-' for the standard input)")
+
+	releasesCmd.AddCommand(releasesListCmd)
+	releasesCmd.AddCommand(releasesShowCmd)
+	releasesCmd.AddCommand(releasesTagCmd)
+	releasesCmd.AddCommand(releasesNotesCmd)
+	releasesCmd.AddCommand(releasesDeleteCmd)
+}
+
+// newReleasesClient returns the deployments client and the authentication
+// token of the releases commands
+func newReleasesClient(cmd *cobra.Command) (*deployments.Client, string, error) {
+	server := viper.GetString(argRootServer)
+	if server == "" {
+		return nil, "", errors.New("No server")
+	}
+
+	skipVerify, err := cmd.Flags().GetBool(argRootSkipVerify)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := getAuthToken(cmd)
+	if err != nil {
+		return nil, "", err
+	}
+	return deployments.NewClient(server, skipVerify), token, nil
+}
+
+// ReleasesListCmd handles the releases list command
+type ReleasesListCmd struct {
+	client  *deployments.Client
+	token   string
+	filter  deployments.ReleasesFilter
+	rawMode bool
+	output  io.Writer
+}
+
+// NewReleasesListCmd returns a new ReleasesListCmd
+func NewReleasesListCmd(cmd *cobra.Command, args []string) (*ReleasesListCmd, error) {
+	flags := cmd.Flags()
+
+	perPage, err := flags.GetInt(argPerPage)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := flags.GetInt(argPage)
+	if err != nil {
+		return nil, err
+	}
+
+	if page <= 0 || perPage <= 0 {
+		return nil, errors.New("page and per-page arguments must be larger than 0")
+	}
+
+	name, err := flags.GetString(argReleaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := flags.GetString(argReleaseTag)
+	if err != nil {
+		return nil, err
+	}
+
+	rawMode, err := flags.GetBool(argRawMode)
+	if err != nil {
+		return nil, err
+	}
+
+	client, token, err := newReleasesClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReleasesListCmd{
+		client: client,
+		token:  token,
+		filter: deployments.ReleasesFilter{
+			Name:    name,
+			Tag:     tag,
+			Page:    page,
+			PerPage: perPage,
+		},
+		rawMode: rawMode,
+		output:  os.Stdout,
+	}, nil
+}
+
+// Run executes the command
+func (c *ReleasesListCmd) Run() error {
+	releases, err := c.client.ListReleases(c.token, c.filter)
+	if err != nil {
+		return errors.Wrap(err, "unable to list the releases")
+	}
+	if c.rawMode {
+		return printJSON(c.output, releases)
+	}
+
+	w := tabwriter.NewWriter(c.output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tARTIFACTS\tTAGS\tMODIFIED")
+	for _, r := range releases {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", r.Name, r.ArtifactsCount,
+			strings.Join(r.Tags, ","), r.Modified.Local().Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+// ReleasesShowCmd handles the releases show command
+type ReleasesShowCmd struct {
+	client  *deployments.Client
+	token   string
+	name    string
+	rawMode bool
+	output  io.Writer
+}
+
+// NewReleasesShowCmd returns a new ReleasesShowCmd
+func NewReleasesShowCmd(cmd *cobra.Command, args []string) (*ReleasesShowCmd, error) {
+	rawMode, err := cmd.Flags().GetBool(argRawMode)
+	if err != nil {
+		return nil, err
+	}
+
+	client, token, err := newReleasesClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReleasesShowCmd{
+		client:  client,
+		token:   token,
+		name:    args[0],
+		rawMode: rawMode,
+		output:  os.Stdout,
+	}, nil
+}
+
+// Run executes the command
+func (c *ReleasesShowCmd) Run() error {
+	release, err := c.client.GetRelease(c.token, c.name)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get the release %s", c.name)
+	}
+	if c.rawMode {
+		return printJSON(c.output, release)
+	}
+	return printRelease(c.output, release)
+}
+
+func printRelease(out io.Writer, r *deployments.Release) error {
+	fmt.Fprintf(out, "Name: %s\n", r.Name)
+	fmt.Fprintf(out, "Modified: %s\n", r.Modified.Local().Format(time.RFC3339))
+	fmt.Fprintf(out, "Tags: %s\n", strings.Join(r.Tags, ", "))
+	fmt.Fprintln(out, "Notes:")
+	if r.Notes != "" {
+		for _, line := range strings.Split(strings.TrimRight(r.Notes, "\n"), "\n") {
+			fmt.Fprintf(out, "  %s\n", line)
+		}
+	}
+	fmt.Fprintln(out, "Artifacts:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  ID\tDEVICE TYPES\tUPDATE TYPES\tSIZE\tSIGNED")
+	for _, a := range r.Artifacts {
+		updateTypes := make([]string, 0, len(a.Updates))
+		for _, u := range a.Updates {
+			updateTypes = append(updateTypes, u.TypeInfo.Type)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%d\t%t\n", a.ID,
+			strings.Join(a.DeviceTypesCompatible, ","), strings.Join(updateTypes, ","),
+			a.Size, a.Signed)
+	}
+	return w.Flush()
+}
+
+// ReleasesTagCmd handles the releases tag command
+type ReleasesTagCmd struct {
+	client *deployments.Client
+	token  string
+	name   string
+	tags   []string
+}
+
+// NewReleasesTagCmd returns a new ReleasesTagCmd
+func NewReleasesTagCmd(cmd *cobra.Command, args []string) (*ReleasesTagCmd, error) {
+	client, token, err := newReleasesClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReleasesTagCmd{
+		client: client,
+		token:  token,
+		name:   args[0],
+		tags:   args[1:],
+	}, nil
+}
+
+// Run executes the command
+func (c *ReleasesTagCmd) Run() error {
+	if err := c.client.SetReleaseTags(c.token, c.name, c.tags); err != nil {
+		return errors.Wrapf(err, "unable to tag the release %s", c.name)
+	}
+	log.Info("tags updated")
+	return nil
+}
+
+// ReleasesNotesCmd handles the releases notes command
+type ReleasesNotesCmd struct {
+	client    *deployments.Client
+	token     string
+	name      string
+	notesFile string
+	input     io.Reader
+	output    io.Writer
+}
+
+// NewReleasesNotesCmd returns a new ReleasesNotesCmd
+func NewReleasesNotesCmd(cmd *cobra.Command, args []string) (*ReleasesNotesCmd, error) {
+	notesFile, err := cmd.Flags().GetString(argReleaseNotes)
+	if err != nil {
+		return nil, err
+	}
+
+	client, token, err := newReleasesClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReleasesNotesCmd{
+		client:    client,
+		token:     token,
+		name:      args[0],
+		notesFile: notesFile,
+		input:     os.Stdin,
+		output:    os.Stdout,
+	}, nil
+}
+
+// Run executes the command
+func (c *ReleasesNotesCmd) Run() error {
+	if c.notesFile == "" {
+		release, err := c.client.GetRelease(c.token, c.name)
+		if err != nil {
+			return errors.Wrapf(err, "unable to get the release %s", c.name)
+		}
+		if release.Notes != "" {
+			fmt.Fprintln(c.output, strings.TrimRight(release.Notes, "\n"))
+		}
+		return nil
+	}
+
+	var notes []byte
+	var err error
+	if c.notesFile == "-" {
+		notes, err = io.ReadAll(c.input)
+	} else {
+		notes, err = os.ReadFile(c.notesFile)
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to read the release notes")
+	}
+	err = c.client.UpdateReleaseNotes(c.token, c.name, strings.TrimRight(string(notes), "\n"))
+	if err != nil {
+		return errors.Wrapf(err, "unable to update the notes of the release %s", c.name)
+	}
+	log.Info("notes updated")
+	return nil
+}
+
+// ReleasesDeleteCmd handles the releases delete command
+type ReleasesDeleteCmd struct {
+	client *deployments.Client
+	token  string
+	names  []string
+}
+
+// NewReleasesDeleteCmd returns a new ReleasesDeleteCmd
+func NewReleasesDeleteCmd(cmd *cobra.Command, args []string) (*ReleasesDeleteCmd, error) {
+	client, token, err := newReleasesClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReleasesDeleteCmd{
+		client: client,
+		token:  token,
+		names:  args,
+	}, nil
+}
+
+// Run executes the command
+func (c *ReleasesDeleteCmd) Run() error {
+	if err := c.client.DeleteReleases(c.token, c.names); err != nil {
+		return errors.Wrap(err, "unable to delete the releases")
+	}
+	log.Info("delete successful")
+	return nil
+}
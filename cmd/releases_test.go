@@ -0,0 +1,64 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mendersoftware/mender-cli/client/deployments"
+)
+
+func TestPrintRelease(t *testing.T) {
+	t.Parallel()
+	release := &deployments.Release{}
+	err := json.Unmarshal([]byte(`{
+		"name": "release-1",
+		"tags": ["prod", "v1"],
+		"notes": "Fixes\nthe update\n",
+		"artifacts": [{
+			"id": "1234",
+			"device_types_compatible": ["rpi4", "rpi5"],
+			"updates": [{"type_info": {"type": "rootfs-image"}}],
+			"size": 1024,
+			"signed": true
+		}]
+	}`), release)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := printRelease(&buf, release); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	lines := strings.Split(buf.String(), "\n")
+	expected := []string{
+		"Name: release-1",
+		"Tags: prod, v1",
+		"Notes:",
+		"  Fixes",
+		"  the update",
+		"Artifacts:",
+		"  ID    DEVICE TYPES  UPDATE TYPES  SIZE  SIGNED",
+		"  1234  rpi4,rpi5     rootfs-image  1024  true",
+	}
+	// skip the modification time, which is shown in the local time zone
+	lines = append(lines[:1], lines[2:]...)
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n")+"\n" {
+		t.Errorf("Unexpected output:\n%s", buf.String())
+	}
+}
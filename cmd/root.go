@@ -134,9 +134,13 @@ func init() {
 	_ = rootCmd.Flags().MarkHidden(argRootGenerate)
 	rootCmd.AddCommand(loginCmd)
 	rootCmd.AddCommand(artifactsCmd)
+	rootCmd.AddCommand(releasesCmd)
 	rootCmd.AddCommand(devicesCmd)
 	rootCmd.AddCommand(terminalCmd)
 	rootCmd.AddCommand(portForwardCmd)
 	rootCmd.AddCommand(fileTransferCmd)
+	rootCmd.AddCommand(fsCmd)
+	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(sessionsCmd)
 	validateConfiguration()
 }
@@ -0,0 +1,272 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mendersoftware/mender-cli/client/auditlogs"
+	"github.com/mendersoftware/mender-cli/client/deviceconnect"
+	"github.com/mendersoftware/mender-cli/log"
+)
+
+const (
+	argSave = "save"
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List and play back the terminal sessions recorded by the server.",
+	Long: "List and play back the remote terminal sessions recorded by the\n" +
+		"server. The sessions of a device are listed from the audit logs,\n" +
+		"while the recordings are downloaded from the deviceconnect service.",
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list DEVICE_ID",
+	Short: "List the terminal sessions of a device, the most recent first.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewSessionsListCmd(c, args)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+var sessionsPlaybackCmd = &cobra.Command{
+	Use:   "playback [flags] SESSION_ID",
+	Short: "Play back the recording of a terminal session.",
+	Long: "Download and play back the recording of a terminal session.\n\n" +
+		"The playback uses the same keys as terminal --playback. With --save,\n" +
+		"the recording is saved in the local recording format instead of\n" +
+		"being played back, and can be played later with terminal --playback.",
+	Args: cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewSessionsPlaybackCmd(c, args)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+func init() {
+	sessionsPlaybackCmd.Flags().Float64P(argSpeed, "", 1, "playback speed multiplier")
+	sessionsPlaybackCmd.Flags().DurationP(argMaxIdle, "", 0,
+		"limit the idle time between frames during playback (0 for no limit)")
+	sessionsPlaybackCmd.Flags().StringP(argSave, "", "",
+		"save the recording to this file instead of playing it back")
+
+	sessionsCmd.AddCommand(sessionsListCmd)
+	sessionsCmd.AddCommand(sessionsPlaybackCmd)
+}
+
+// SessionsListCmd handles the sessions list command
+type SessionsListCmd struct {
+	server     string
+	token      string
+	skipVerify bool
+	deviceID   string
+	output     io.Writer
+}
+
+// NewSessionsListCmd returns a new SessionsListCmd
+func NewSessionsListCmd(cmd *cobra.Command, args []string) (*SessionsListCmd, error) {
+	server := viper.GetString(argRootServer)
+	if server == "" {
+		return nil, errors.New("No server")
+	}
+
+	skipVerify, err := cmd.Flags().GetBool(argRootSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := getAuthToken(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SessionsListCmd{
+		server:     server,
+		token:      token,
+		skipVerify: skipVerify,
+		deviceID:   args[0],
+		output:     os.Stdout,
+	}, nil
+}
+
+// Run executes the command
+func (c *SessionsListCmd) Run() error {
+	client := auditlogs.NewClient(c.server, c.skipVerify)
+	sessions, err := client.ListTerminalSessions(c.token, c.deviceID)
+	if err != nil {
+		return errors.Wrap(err, "unable to list the sessions")
+	}
+
+	w := tabwriter.NewWriter(c.output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SESSION ID\tUSER\tSTARTED\tDURATION")
+	for _, s := range sessions {
+		duration := "-"
+		if !s.EndTime.IsZero() && !s.StartTime.IsZero() {
+			duration = s.EndTime.Sub(s.StartTime).Round(time.Second).String()
+		}
+		started := "-"
+		if !s.StartTime.IsZero() {
+			started = s.StartTime.Local().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.ID, s.User, started, duration)
+	}
+	return w.Flush()
+}
+
+// SessionsPlaybackCmd handles the sessions playback command
+type SessionsPlaybackCmd struct {
+	server          string
+	token           string
+	skipVerify      bool
+	sessionID       string
+	playbackSpeed   float64
+	playbackMaxIdle time.Duration
+	saveFile        string
+}
+
+// NewSessionsPlaybackCmd returns a new SessionsPlaybackCmd
+func NewSessionsPlaybackCmd(cmd *cobra.Command, args []string) (*SessionsPlaybackCmd, error) {
+	server := viper.GetString(argRootServer)
+	if server == "" {
+		return nil, errors.New("No server")
+	}
+
+	skipVerify, err := cmd.Flags().GetBool(argRootSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	playbackSpeed, err := cmd.Flags().GetFloat64(argSpeed)
+	if err != nil {
+		return nil, err
+	}
+	if playbackSpeed <= 0 {
+		return nil, errors.New("speed argument must be larger than 0")
+	}
+
+	playbackMaxIdle, err := cmd.Flags().GetDuration(argMaxIdle)
+	if err != nil {
+		return nil, err
+	}
+
+	saveFile, err := cmd.Flags().GetString(argSave)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := getAuthToken(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SessionsPlaybackCmd{
+		server:          server,
+		token:           token,
+		skipVerify:      skipVerify,
+		sessionID:       args[0],
+		playbackSpeed:   playbackSpeed,
+		playbackMaxIdle: playbackMaxIdle,
+		saveFile:        saveFile,
+	}, nil
+}
+
+// Run executes the command
+func (c *SessionsPlaybackCmd) Run() error {
+	client := deviceconnect.NewClient(c.server, c.token, c.skipVerify)
+	frames, err := client.GetSessionRecording(c.sessionID)
+	if err != nil {
+		return err
+	}
+	recording := sessionRecording(frames)
+
+	if c.saveFile != "" {
+		f, err := os.OpenFile(c.saveFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := writeTerminalRecording(f, recording); err != nil {
+			return err
+		}
+		log.Infof("Saved the session %s to %s\n", c.sessionID, c.saveFile)
+		return nil
+	}
+
+	log.Info(fmt.Sprintf("Playing back the session: %s", c.sessionID))
+	return playTerminalRecording(recording, os.Stdout, c.playbackSpeed, c.playbackMaxIdle)
+}
+
+// sessionRecording converts a session recording downloaded from the server
+// to a terminal recording
+func sessionRecording(frames []deviceconnect.SessionFrame) *terminalRecording {
+	recording := &terminalRecording{
+		Header: TerminalRecordingHeader{
+			Version:        terminalRecordingVersion,
+			TerminalWidth:  defaultTermWidth,
+			TerminalHeight: defaultTermHeight,
+		},
+	}
+	copy(recording.Header.TerminalType[:], []byte(terminalTypeDefault))
+
+	// recordings without delays are played back at a fixed pace, like the
+	// version 1 recordings
+	timed := false
+	for _, frame := range frames {
+		if frame.Offset > 0 {
+			timed = true
+			break
+		}
+	}
+
+	sized := false
+	for i, frame := range frames {
+		o := TerminalRecordingData{Offset: frame.Offset}
+		if !timed {
+			o.Offset = time.Duration(i) * playbackSleep
+		}
+		switch frame.Type {
+		case deviceconnect.SessionFrameOutput:
+			o.Type = terminalRecordingOutput
+			o.Data = frame.Data
+		case deviceconnect.SessionFrameResize:
+			// the first size is the initial size of the terminal
+			if !sized && frame.Offset == 0 {
+				recording.Header.TerminalWidth = int16(frame.TerminalWidth)
+				recording.Header.TerminalHeight = int16(frame.TerminalHeight)
+				sized = true
+				continue
+			}
+			o.Type = terminalRecordingResize
+			o.TerminalWidth = int16(frame.TerminalWidth)
+			o.TerminalHeight = int16(frame.TerminalHeight)
+		default:
+			continue
+		}
+		recording.Records = append(recording.Records, o)
+	}
+	return recording
+}
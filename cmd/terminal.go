@@ -15,6 +15,7 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"compress/gzip"
 	"context"
 	"encoding/binary"
@@ -23,6 +24,8 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -44,22 +47,63 @@ const (
 	defaultTermWidth  = 80
 	defaultTermHeight = 40
 
-	// dummy delay for playback
+	// delay between records when playing back recordings without timing
+	// information (version 1)
 	playbackSleep = time.Millisecond * 32
 
 	// cli args
-	argRecord   = "record"
-	argPlayback = "playback"
+	argRecord      = "record"
+	argPlayback    = "playback"
+	argSpeed       = "speed"
+	argMaxIdle     = "max-idle"
+	argRecordInput = "record-input"
+	argRedactInput = "redact-input"
+	argNoReconnect = "no-reconnect"
+	argEscapeChar  = "escape-char"
+	argShare       = "share"
+	argShareMode   = "share-mode"
+	argShareAllow  = "share-allow"
+	argAttach      = "attach"
+
+	// reconnection backoff and attempts when the connection is lost
+	terminalReconnectMinBackoff  = time.Second
+	terminalReconnectMaxBackoff  = 30 * time.Second
+	terminalReconnectMaxAttempts = 10
 )
 
 var terminalCmd = &cobra.Command{
-	Use:   "terminal [DEVICE_ID]",
+	Use:   "terminal [flags] [DEVICE_ID]",
 	Short: "Remotely access a terminal on a device",
 	Long: "Remotely access a terminal on a device\n" +
 		"Basic usage is terminal DEVICE_ID, which starts a new terminal " +
 		"session with the remote device. The session can be saved locally " +
 		"using --record flag. When using --playback flag, no DEVICE_ID is " +
-		"required and no connection will be established.",
+		"required and no connection will be established. Both recordings " +
+		"saved with --record and asciicast v2 files can be played back.\n\n" +
+		"With --record-input, the keys typed during the session are recorded " +
+		"as well. Unless --redact-input=false is given, the input which is " +
+		"not echoed back by the device, like passwords, is masked before " +
+		"being saved.\n\n" +
+		"When the connection with the device is lost, the session is " +
+		"reconnected and a new shell is started, unless --no-reconnect is " +
+		"given.\n\n" +
+		"Like in ssh, the escape character (~ by default) typed at the " +
+		"beginning of a line starts a local command: ~. disconnects, ~r " +
+		"starts, pauses or resumes the recording, ~i shows the session " +
+		"information, ~f uploads or downloads a file, ~~ sends the escape " +
+		"character and ~? lists the commands.\n\n" +
+		"With --share, the session is exposed over a Unix socket, which " +
+		"another mender-cli on the same host can attach to with --attach, " +
+		"without DEVICE_ID, to watch the session or, with " +
+		"--share-mode read-write, to type in it as well. Only the current " +
+		"user can attach, and the users and groups given with --share-allow " +
+		"user:USER or group:GROUP, whose processes are identified by their " +
+		"credentials when they connect; the directory of the socket must be " +
+		"accessible to them as well.\n\n" +
+		"During playback, the following keys are available: space to pause " +
+		"and resume, '.' to step to the next frame while paused, '+' and '-' " +
+		"to change the speed, the left and right arrows to seek backward and " +
+		"forward, and 'q' to quit.",
 	Args: cobra.RangeArgs(0, 1),
 	Run: func(c *cobra.Command, args []string) {
 		cmd, err := NewTerminalCmd(c, args)
@@ -72,6 +116,26 @@ func init() {
 	terminalCmd.Flags().StringP(argRecord, "", "", "recording file path to save the session to")
 	terminalCmd.Flags().
 		StringP(argPlayback, "", "", "recording file path to playback the session from")
+	terminalCmd.Flags().BoolP(argRecordInput, "", false,
+		"record the terminal input as well as the output")
+	terminalCmd.Flags().BoolP(argRedactInput, "", true,
+		"mask the recorded input which is not echoed back by the device")
+	terminalCmd.Flags().StringP(argEscapeChar, "e", defaultEscapeChar,
+		"escape character for the session commands, or \"none\" to disable them")
+	terminalCmd.Flags().StringP(argShare, "", "",
+		"share the session with other local mender-cli instances over this Unix socket")
+	terminalCmd.Flags().StringP(argShareMode, "", shareModeReadOnly,
+		"access of the clients attached to the shared session [read-only|read-write]")
+	terminalCmd.Flags().StringSliceP(argShareAllow, "", nil,
+		"also allow this user:USER or group:GROUP to attach to the shared session (repeatable)")
+	terminalCmd.Flags().StringP(argAttach, "", "",
+		"attach to a session shared over this Unix socket")
+	terminalCmd.Flags().BoolP(argNoReconnect, "", false,
+		"end the session instead of reconnecting when the connection is lost")
+	terminalCmd.Flags().Float64P(argSpeed, "", 1, "playback speed multiplier")
+	terminalCmd.Flags().DurationP(argMaxIdle, "", 0,
+		"limit the idle time between frames during playback (0 for no limit)")
+	terminalCmd.AddCommand(terminalExportCmd)
 }
 
 // TerminalCmd handles the terminal command
@@ -84,12 +148,30 @@ type TerminalCmd struct {
 	running            bool
 	healthcheck        chan int
 	stop               chan struct{}
+	stopOnce           sync.Once
+	reconnectEnabled   bool
+	escapeChar         byte
+	client             atomic.Pointer[deviceconnect.Client]
+	shareSocket        string
+	shareReadWrite     bool
+	shareAllowed       *unixAllowList
+	share              *terminalShare
+	attachSocket       string
 	err                error
 	recordFile         string
-	recording          bool
+	recording          atomic.Bool
+	recordingPaused    atomic.Bool
+	recordInput        bool
+	redactInput        bool
 	stopRecording      chan bool
+	recordingMutex     sync.Mutex
+	recordingStopped   chan struct{}
 	playbackFile       string
+	playbackSpeed      float64
+	playbackMaxIdle    time.Duration
 	terminalOutputChan chan []byte
+	terminalInputChan  chan []byte
+	terminalResizeChan chan [2]int
 }
 
 const (
@@ -108,7 +190,7 @@ type TerminalRecordingHeader struct {
 }
 
 const (
-	terminalRecordingVersion = 1
+	terminalRecordingVersion = 2
 )
 
 type TerminalRecordingType int8
@@ -116,10 +198,19 @@ type TerminalRecordingType int8
 type TerminalRecordingData struct {
 	Type TerminalRecordingType
 	Data []byte
+	// Offset is the time elapsed since the start of the recording
+	// (version 2 and later)
+	Offset time.Duration
+	// TerminalWidth and TerminalHeight are the new terminal size of
+	// resize records (version 2 and later)
+	TerminalWidth  int16
+	TerminalHeight int16
 }
 
 const (
 	terminalRecordingOutput TerminalRecordingType = iota
+	terminalRecordingResize
+	terminalRecordingInput
 )
 
 // NewTerminalCmd returns a new TerminalCmd
@@ -139,11 +230,78 @@ func NewTerminalCmd(cmd *cobra.Command, args []string) (*TerminalCmd, error) {
 		return nil, err
 	}
 
+	recordInput, err := cmd.Flags().GetBool(argRecordInput)
+	if err != nil {
+		return nil, err
+	}
+
+	redactInput, err := cmd.Flags().GetBool(argRedactInput)
+	if err != nil {
+		return nil, err
+	}
+
+	noReconnect, err := cmd.Flags().GetBool(argNoReconnect)
+	if err != nil {
+		return nil, err
+	}
+
+	escapeCharArg, err := cmd.Flags().GetString(argEscapeChar)
+	if err != nil {
+		return nil, err
+	}
+	var escapeChar byte
+	if escapeCharArg != escapeCharNone {
+		if len(escapeCharArg) != 1 {
+			return nil, errors.New("escape-char argument must be a single character or none")
+		}
+		escapeChar = escapeCharArg[0]
+	}
+
+	shareSocket, err := cmd.Flags().GetString(argShare)
+	if err != nil {
+		return nil, err
+	}
+
+	shareMode, err := cmd.Flags().GetString(argShareMode)
+	if err != nil {
+		return nil, err
+	}
+	if shareMode != shareModeReadOnly && shareMode != shareModeReadWrite {
+		return nil, errors.New("share-mode argument must be read-only or read-write")
+	}
+
+	shareAllow, err := cmd.Flags().GetStringSlice(argShareAllow)
+	if err != nil {
+		return nil, err
+	}
+	shareAllowed, err := parseUnixAllowList(shareAllow)
+	if err != nil {
+		return nil, errors.Wrap(err, "share-allow argument")
+	}
+
+	attachSocket, err := cmd.Flags().GetString(argAttach)
+	if err != nil {
+		return nil, err
+	}
+
 	playbackFile, err := cmd.Flags().GetString(argPlayback)
 	if err != nil {
 		return nil, err
 	}
 
+	playbackSpeed, err := cmd.Flags().GetFloat64(argSpeed)
+	if err != nil {
+		return nil, err
+	}
+	if playbackSpeed <= 0 {
+		return nil, errors.New("speed argument must be larger than 0")
+	}
+
+	playbackMaxIdle, err := cmd.Flags().GetDuration(argMaxIdle)
+	if err != nil {
+		return nil, err
+	}
+
 	token, err := getAuthToken(cmd)
 	if err != nil {
 		return nil, err
@@ -154,7 +312,7 @@ func NewTerminalCmd(cmd *cobra.Command, args []string) (*TerminalCmd, error) {
 		deviceID = args[0]
 	}
 
-	if playbackFile == "" && deviceID == "" {
+	if playbackFile == "" && attachSocket == "" && deviceID == "" {
 		return nil, errors.New("No device specified")
 	}
 
@@ -164,11 +322,23 @@ func NewTerminalCmd(cmd *cobra.Command, args []string) (*TerminalCmd, error) {
 		skipVerify:         skipVerify,
 		deviceID:           deviceID,
 		healthcheck:        make(chan int),
-		stop:               make(chan struct{}),
+		stop:               make(chan struct{}, 1),
+		reconnectEnabled:   !noReconnect,
+		escapeChar:         escapeChar,
+		shareSocket:        shareSocket,
+		shareReadWrite:     shareMode == shareModeReadWrite,
+		shareAllowed:       shareAllowed,
+		attachSocket:       attachSocket,
 		recordFile:         recordFile,
+		recordInput:        recordInput,
+		redactInput:        redactInput,
 		stopRecording:      make(chan bool),
 		terminalOutputChan: make(chan []byte),
+		terminalInputChan:  make(chan []byte),
+		terminalResizeChan: make(chan [2]int),
 		playbackFile:       playbackFile,
+		playbackSpeed:      playbackSpeed,
+		playbackMaxIdle:    playbackMaxIdle,
 	}, nil
 }
 
@@ -205,25 +375,54 @@ func (c *TerminalCmd) stopShell(client *deviceconnect.Client) error {
 	return nil
 }
 
-func (c *TerminalCmd) record() {
-	f, err := os.Create(c.recordFile)
+// startRecording creates the recording file and starts recording
+func (c *TerminalCmd) startRecording(termWidth, termHeight int) error {
+	f, err := os.OpenFile(c.recordFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
 	if err != nil {
-		log.Err(fmt.Sprintf("Can't create recording file: %s: %s", c.recordFile, err.Error()))
+		return errors.Wrap(err, "Can't create recording file")
+	}
+	stopped := make(chan struct{})
+	c.recordingMutex.Lock()
+	c.recordingStopped = stopped
+	c.recordingMutex.Unlock()
+	c.recording.Store(true)
+	go c.record(f, termWidth, termHeight, stopped)
+	return nil
+}
+
+// recordingDone returns a channel closed when the recording stops, or nil
+// if the session isn't being recorded
+func (c *TerminalCmd) recordingDone() <-chan struct{} {
+	if !c.recording.Load() {
+		return nil
 	}
+	c.recordingMutex.Lock()
+	defer c.recordingMutex.Unlock()
+	return c.recordingStopped
+}
+
+// record writes the recording until it is stopped or fails, then closes
+// stopped; the data sent to the recording afterwards is dropped
+func (c *TerminalCmd) record(f *os.File, termWidth, termHeight int, stopped chan struct{}) {
+	defer func() {
+		c.recording.Store(false)
+		close(stopped)
+	}()
 	defer f.Close()
 
 	fz := gzip.NewWriter(f)
 	defer fz.Close()
 
+	start := time.Now()
 	data := TerminalRecordingHeader{
 		Version:        terminalRecordingVersion,
-		Timestamp:      time.Now().Unix(),
-		TerminalWidth:  defaultTermWidth,
-		TerminalHeight: defaultTermHeight,
+		Timestamp:      start.Unix(),
+		TerminalWidth:  int16(termWidth),
+		TerminalHeight: int16(termHeight),
 	}
 	copy(data.DeviceID[:], []byte(c.deviceID))
 	copy(data.TerminalType[:], []byte(terminalTypeDefault))
-	err = binary.Write(fz, binary.LittleEndian, data)
+	err := binary.Write(fz, binary.LittleEndian, data)
 	if err != nil {
 		log.Err(fmt.Sprintf("Header write failed: %s", err.Error()))
 	}
@@ -232,25 +431,70 @@ func (c *TerminalCmd) record() {
 		log.Err(fmt.Sprintf("Header flush failed: %s", err.Error()))
 	}
 
-	log.Info(fmt.Sprintf("Recording to file: %s", c.recordFile))
+	// the input is held back by the redactor until it is echoed back
+	var redactor *inputRedactor
+	if c.recordInput && c.redactInput {
+		redactor = newInputRedactor(inputEchoTimeout)
+	}
 
 	e := gob.NewEncoder(fz)
+	encode := func(records ...TerminalRecordingData) bool {
+		for _, o := range records {
+			if err := e.Encode(o); err != nil {
+				log.Err(fmt.Sprintf("Error encoding %q: %s", string(o.Data), err.Error()))
+				return false
+			}
+		}
+		fz.Flush()
+		return true
+	}
 	for {
+		var o TerminalRecordingData
+		var expire <-chan time.Time
+		if redactor != nil {
+			if deadline, ok := redactor.Deadline(); ok {
+				expire = time.After(deadline - time.Since(start))
+			}
+		}
 		select {
 		case <-c.stopRecording:
+			if redactor != nil {
+				encode(redactor.Flush()...)
+			}
 			return
+		case <-expire:
+			if !encode(redactor.Expire(time.Since(start))...) {
+				return
+			}
+			continue
 		case terminalOutput := <-c.terminalOutputChan:
-			o := TerminalRecordingData{
+			o = TerminalRecordingData{
 				Type: terminalRecordingOutput,
 				Data: terminalOutput,
 			}
-			err = e.Encode(o)
-			fz.Flush()
-			if err != nil {
-				log.Err(fmt.Sprintf("Error encoding %q: %s", string(terminalOutput), err.Error()))
-				return
+		case terminalInput := <-c.terminalInputChan:
+			o = TerminalRecordingData{
+				Type: terminalRecordingInput,
+				Data: terminalInput,
+			}
+		case size := <-c.terminalResizeChan:
+			o = TerminalRecordingData{
+				Type:           terminalRecordingResize,
+				TerminalWidth:  int16(size[0]),
+				TerminalHeight: int16(size[1]),
 			}
 		}
+		if c.recordingPaused.Load() {
+			continue
+		}
+		o.Offset = time.Since(start)
+		records := []TerminalRecordingData{o}
+		if redactor != nil {
+			records = redactor.Add(o)
+		}
+		if !encode(records...) {
+			return
+		}
 	}
 }
 
@@ -262,57 +506,72 @@ func (c *TerminalCmd) playback(w io.Writer) error {
 	}
 	defer f.Close()
 
-	fz, err := gzip.NewReader(f)
+	recording, err := readAnyTerminalRecording(f)
 	if err != nil {
+		log.Err(err.Error())
 		return err
 	}
-	defer fz.Close()
 
-	var header TerminalRecordingHeader
-	err = binary.Read(fz, binary.LittleEndian, &header)
-	if err != nil {
-		log.Err(fmt.Sprintf("Can't read header: %s", err.Error()))
-		return err
-	}
+	log.Info(fmt.Sprintf("Playing back from file: %s", c.playbackFile))
+	return playTerminalRecording(recording, w, c.playbackSpeed, c.playbackMaxIdle)
+}
 
+// playTerminalRecording plays back a recording, reading the playback
+// control keys from the standard input when it is a terminal
+func playTerminalRecording(
+	recording *terminalRecording,
+	w io.Writer,
+	speed float64,
+	maxIdle time.Duration,
+) error {
+	header := recording.Header
 	dateTime := time.Unix(header.Timestamp, 0)
+	player := newTerminalPlayer(recording, w, speed, maxIdle)
 
-	log.Info(fmt.Sprintf("Playing back from file: %s", c.playbackFile))
-	log.Info(fmt.Sprintf("Device ID: %s", string(header.DeviceID[:])))
-	log.Info(fmt.Sprintf("Terminal type: %s", string(header.TerminalType[:])))
+	log.Info(fmt.Sprintf("Device ID: %s", string(bytes.TrimRight(header.DeviceID[:], "\x00"))))
+	log.Info(fmt.Sprintf("Terminal type: %s",
+		string(bytes.TrimRight(header.TerminalType[:], "\x00"))))
 	log.Info(fmt.Sprintf("Terminal size: %dx%d", header.TerminalWidth, header.TerminalHeight))
 	log.Info(fmt.Sprintf("Timestamp: %s", dateTime.Format(time.UnixDate)))
+	log.Info(fmt.Sprintf("Duration: %s", player.Duration().Round(time.Second)))
 	log.Info("")
 
-	d := gob.NewDecoder(fz)
-	for {
-		var o TerminalRecordingData
-		err = d.Decode(&o)
+	ctx, cancelContext := context.WithCancel(context.Background())
+	defer cancelContext()
+
+	var keys chan int
+	stdinID := int(os.Stdin.Fd())
+	if term.IsTerminal(stdinID) {
+		oldState, err := term.MakeRaw(stdinID)
 		if err != nil {
-			if err != io.EOF {
-				log.Err(fmt.Sprintf("Decoding error: %s", err.Error()))
-				return err
-			}
-			break
-		}
-		if o.Type == terminalRecordingOutput {
-			_, err = w.Write(o.Data)
-			if err != nil {
-				log.Err(fmt.Sprintf("Writting error: %s", err.Error()))
-				return err
-			}
+			return errors.Wrap(err, "Unable to set the terminal in raw mode")
 		}
-		time.Sleep(playbackSleep)
+		defer func() {
+			_ = term.Restore(stdinID, oldState)
+		}()
+		keys = make(chan int)
+		go readPlaybackKeys(ctx, os.Stdin, keys)
 	}
+
+	// handle CTRL+C and signals
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, unix.SIGINT, unix.SIGTERM)
+	defer signal.Stop(quit)
+	go func() {
+		select {
+		case <-quit:
+			cancelContext()
+		case <-ctx.Done():
+		}
+	}()
+
+	err := player.Play(ctx, keys)
 	log.Info("\r")
-	return nil
+	return err
 }
 
 // Run executes the command
 func (c *TerminalCmd) Run() error {
-	ctx, cancelContext := context.WithCancel(context.Background())
-	defer cancelContext()
-
 	// get the terminal width and height
 	termWidth := defaultTermWidth
 	termHeight := defaultTermHeight
@@ -327,11 +586,16 @@ func (c *TerminalCmd) Run() error {
 		}
 	}
 
-	// start recording when applicable
+	// when attaching to a shared session, no connection is established
+	if c.attachSocket != "" {
+		return c.attach()
+	}
+
+	// check the recording file when applicable
+	record := false
 	if _, err := os.Stat(c.recordFile); os.IsNotExist(err) {
 		if len(c.recordFile) > 0 {
-			c.recording = true
-			go c.record()
+			record = true
 		}
 	} else {
 		log.Err(fmt.Sprintf(
@@ -340,36 +604,28 @@ func (c *TerminalCmd) Run() error {
 		))
 	}
 
-	client := deviceconnect.NewClient(c.server, c.token, c.skipVerify)
-
-	// check if the device is connected
-	device, err := client.GetDevice(c.deviceID)
-	if err != nil {
-		return errors.Wrap(err, "unable to get the device")
-	} else if device.Status != deviceconnect.CONNECTED {
-		return errors.New("the device is not connected")
-	}
-
-	// connect to the websocket and start the ping-pong connection health-check
-	err = client.Connect(c.deviceID, c.token)
+	client, err := c.connect()
 	if err != nil {
 		return err
 	}
 
-	go client.PingPong(ctx)
-	defer client.Close()
-
 	// set the terminal in raw mode
 	if term.IsTerminal(termID) {
 		termWidth, termHeight, err = term.GetSize(termID)
 		if err != nil {
+			client.Close()
 			return errors.Wrap(err, "Unable to get the terminal size")
 		}
 
 		fmt.Fprintln(os.Stderr, "Press CTRL+] to quit the session")
+		if c.escapeChar != 0 {
+			fmt.Fprintf(os.Stderr, "Type %c? at the beginning of a line for the session commands\n",
+				c.escapeChar)
+		}
 
 		oldState, err := term.MakeRaw(termID)
 		if err != nil {
+			client.Close()
 			return errors.Wrap(err, "Unable to set the terminal in raw mode")
 		}
 		defer func() {
@@ -377,17 +633,169 @@ func (c *TerminalCmd) Run() error {
 		}()
 	}
 
+	// start recording when applicable
+	if record {
+		if err := c.startRecording(termWidth, termHeight); err != nil {
+			log.Err(err.Error())
+		} else {
+			log.Info(fmt.Sprintf("Recording to file: %s", c.recordFile))
+		}
+	}
+
+	// handle CTRL+C and signals
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, unix.SIGINT, unix.SIGTERM)
+	defer signal.Stop(quit)
+
+	// the standard input is read for the whole command, across reconnections
+	msgChan := make(chan *ws.ProtoMsg)
+	c.running = true
+	go c.pipeStdin(msgChan, os.Stdin)
+
+	// share the session when applicable
+	if c.shareSocket != "" {
+		mode := shareModeReadOnly
+		if c.shareReadWrite {
+			mode = shareModeReadWrite
+		}
+		banner := fmt.Sprintf("[mender-cli: attached to the session on the device %s, %s]\r\n",
+			c.deviceID, mode)
+		c.share, err = newTerminalShare(c.shareSocket, c.shareAllowed, c.shareReadWrite, banner,
+			func(input []byte) { c.sendInput(msgChan, input) }, c.notify)
+		if err != nil {
+			client.Close()
+			return err
+		}
+		defer c.share.Close()
+		go c.share.Serve()
+		c.notify(fmt.Sprintf("sharing the session (%s) on %s", mode, c.shareSocket))
+	}
+
+	for {
+		err = c.runSession(client, msgChan, quit, termID)
+		if err != errRestart {
+			return err
+		}
+		client, err = c.reconnect(msgChan, quit)
+		if err != nil || client == nil {
+			return err
+		}
+	}
+}
+
+// connect checks that the device is connected and connects to the websocket
+func (c *TerminalCmd) connect() (*deviceconnect.Client, error) {
+	client := deviceconnect.NewClient(c.server, c.token, c.skipVerify)
+
+	// check if the device is connected
+	device, err := client.GetDevice(c.deviceID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the device")
+	} else if device.Status != deviceconnect.CONNECTED {
+		return nil, errors.New("the device is not connected")
+	}
+
+	// connect to the websocket
+	err = client.Connect(c.deviceID, c.token)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// reconnect connects to the device again with an exponential backoff,
+// discarding the input meanwhile; it returns a nil client if the user
+// quits while reconnecting
+func (c *TerminalCmd) reconnect(
+	msgChan chan *ws.ProtoMsg,
+	quit chan os.Signal,
+) (*deviceconnect.Client, error) {
+	backoff := terminalReconnectMinBackoff
+	for attempt := 1; ; attempt++ {
+		c.notify(fmt.Sprintf("reconnecting in %s (attempt %d of %d), press CTRL+] to quit",
+			backoff, attempt, terminalReconnectMaxAttempts))
+		timer := time.NewTimer(backoff)
+	wait:
+		for {
+			select {
+			case <-timer.C:
+				break wait
+			case <-msgChan:
+				// the shell is gone, the input is discarded
+			case <-quit:
+				timer.Stop()
+				return nil, nil
+			case <-c.stop:
+				timer.Stop()
+				return nil, nil
+			}
+		}
+
+		client, err := c.connect()
+		if err == nil {
+			c.notify("reconnected, a new shell session was started")
+			return client, nil
+		} else if attempt >= terminalReconnectMaxAttempts {
+			return nil, errors.Wrap(err, "unable to reconnect to the device")
+		}
+		log.Verbf("reconnection failed: %s", err.Error())
+		backoff = min(backoff*2, terminalReconnectMaxBackoff)
+	}
+}
+
+// notify writes a message about the session to the terminal
+func (c *TerminalCmd) notify(msg string) {
+	fmt.Fprintf(os.Stdout, "\r\n[mender-cli: %s]\r\n", msg)
+}
+
+// runSession spawns a shell on the connected device and runs the session
+// until it ends, returning errRestart if the connection is lost and the
+// session should be reconnected
+func (c *TerminalCmd) runSession(
+	client *deviceconnect.Client,
+	msgChan chan *ws.ProtoMsg,
+	quit chan os.Signal,
+	termID int,
+) error {
+	ctx, cancelContext := context.WithCancel(context.Background())
+	defer cancelContext()
+
+	// start the ping-pong connection health-check
+	go client.PingPong(ctx)
+	defer client.Close()
+
+	termWidth := defaultTermWidth
+	termHeight := defaultTermHeight
+	if term.IsTerminal(termID) {
+		var err error
+		termWidth, termHeight, err = term.GetSize(termID)
+		if err != nil {
+			return errors.Wrap(err, "Unable to get the terminal size")
+		}
+	}
+
 	// start the shell
+	c.client.Store(client)
+	c.sessionID = ""
+	c.err = nil
 	if err := c.startShell(client, termWidth, termHeight); err != nil {
+		if c.reconnectEnabled {
+			c.notify(fmt.Sprintf("unable to start the shell: %s", err.Error()))
+			return errRestart
+		}
 		return err
 	}
 
 	// wait for CTRL+C, signals or stop
-	c.runLoop(ctx, client, termID, termWidth, termHeight)
+	restart := c.runLoop(ctx, client, msgChan, quit, termID, termWidth, termHeight)
 
 	// cancel the context
 	cancelContext()
 
+	if restart {
+		return errRestart
+	}
+
 	// stop shell message
 	if err := c.stopShell(client); err != nil {
 		return err
@@ -397,22 +805,18 @@ func (c *TerminalCmd) Run() error {
 	return c.err
 }
 
-// Run executes the command
+// runLoop forwards the messages to the device until the session ends,
+// returning true if the connection was lost and the session should be
+// reconnected
 func (c *TerminalCmd) runLoop(
 	ctx context.Context,
 	client *deviceconnect.Client,
+	msgChan chan *ws.ProtoMsg,
+	quit chan os.Signal,
 	termID, termWidth, termHeight int,
-) {
-	// message channel
-	msgChan := make(chan *ws.ProtoMsg)
-
-	c.running = true
-	go c.pipeStdin(msgChan, os.Stdin)
-	go c.pipeStdout(msgChan, client, os.Stdout)
-
-	// handle CTRL+C and signals
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, unix.SIGINT, unix.SIGTERM)
+) bool {
+	lost := make(chan error, 1)
+	go c.pipeStdout(ctx, msgChan, lost, client, os.Stdout)
 
 	// resize the terminal window
 	go c.resizeTerminal(ctx, msgChan, termID, termWidth, termHeight)
@@ -423,12 +827,23 @@ func (c *TerminalCmd) runLoop(
 		case msg := <-msgChan:
 			err := client.WriteMessage(msg)
 			if err != nil {
+				if c.reconnectEnabled {
+					c.notify(fmt.Sprintf("connection with the device lost: %s", err.Error()))
+					return true
+				}
 				fmt.Fprintf(os.Stderr, "error: %v\n", err)
 				break
 			}
+		case err := <-lost:
+			c.notify(fmt.Sprintf("connection with the device lost: %s", err.Error()))
+			return true
 		case healthcheckInterval := <-c.healthcheck:
 			healthcheckTimeout = time.Now().Add(time.Duration(healthcheckInterval) * time.Second)
 		case <-time.After(time.Until(healthcheckTimeout)):
+			if c.reconnectEnabled {
+				c.notify("health check failed, connection with the device lost")
+				return true
+			}
 			_ = c.stopShell(client)
 			c.err = errors.New("health check failed, connection with the device lost")
 			c.running = false
@@ -438,6 +853,7 @@ func (c *TerminalCmd) runLoop(
 			c.running = false
 		}
 	}
+	return false
 }
 
 func (c *TerminalCmd) resizeTerminal(
@@ -470,22 +886,40 @@ func (c *TerminalCmd) resizeTerminal(
 						},
 					},
 				}
-				msgChan <- m
+				select {
+				case msgChan <- m:
+				case <-ctx.Done():
+					return
+				}
+				if done := c.recordingDone(); done != nil {
+					select {
+					case c.terminalResizeChan <- [2]int{termWidth, termHeight}:
+					case <-done:
+					case <-ctx.Done():
+						return
+					}
+				}
 			}
 		}
 	}
 }
 
 func (c *TerminalCmd) Stop() {
-	c.running = false
-	c.stop <- struct{}{}
-	if c.recording {
-		c.stopRecording <- true
-	}
+	c.stopOnce.Do(func() {
+		c.running = false
+		c.stop <- struct{}{}
+		if done := c.recordingDone(); done != nil {
+			select {
+			case c.stopRecording <- true:
+			case <-done:
+			}
+		}
+	})
 }
 
 func (c *TerminalCmd) pipeStdin(msgChan chan *ws.ProtoMsg, r io.Reader) {
 	s := bufio.NewReader(r)
+	escape := newEscapeParser(c.escapeChar)
 	for c.running {
 		raw := make([]byte, 1024)
 		n, err := s.Read(raw)
@@ -505,27 +939,54 @@ func (c *TerminalCmd) pipeStdin(msgChan chan *ws.ProtoMsg, r io.Reader) {
 			return
 		}
 
-		m := &ws.ProtoMsg{
-			Header: ws.ProtoHdr{
-				Proto:     ws.ProtoTypeShell,
-				MsgType:   wsshell.MessageTypeShellCommand,
-				SessionID: c.sessionID,
-			},
-			Body: raw[:n],
+		for data := raw[:n]; len(data) > 0 && c.running; {
+			input, command, rest := escape.Next(data)
+			if len(input) > 0 {
+				c.sendInput(msgChan, input)
+			}
+			if command != 0 {
+				rest = c.escapeCommand(command, s, rest)
+			}
+			data = rest
 		}
-		msgChan <- m
 	}
 }
 
+func (c *TerminalCmd) sendInput(msgChan chan *ws.ProtoMsg, input []byte) {
+	m := &ws.ProtoMsg{
+		Header: ws.ProtoHdr{
+			Proto:     ws.ProtoTypeShell,
+			MsgType:   wsshell.MessageTypeShellCommand,
+			SessionID: c.sessionID,
+		},
+		Body: input,
+	}
+	// record the input before sending it, so it precedes its echo
+	if done := c.recordingDone(); done != nil && c.recordInput {
+		select {
+		case c.terminalInputChan <- input:
+		case <-done:
+		}
+	}
+	msgChan <- m
+}
+
 func (c *TerminalCmd) pipeStdout(
+	ctx context.Context,
 	msgChan chan *ws.ProtoMsg,
+	lost chan<- error,
 	client *deviceconnect.Client,
 	w io.Writer,
 ) {
 	for c.running {
 		m, err := client.ReadMessage()
 		if err != nil {
-			if c.running {
+			if ctx.Err() != nil {
+				// the session is over
+				return
+			} else if c.running && c.reconnectEnabled {
+				lost <- err
+			} else if c.running {
 				fmt.Fprintf(os.Stderr, "error: %v\n", err)
 			} else {
 				c.Stop()
@@ -537,14 +998,26 @@ func (c *TerminalCmd) pipeStdout(
 			if _, err := w.Write(m.Body); err != nil {
 				break
 			}
-			if c.recording {
-				c.terminalOutputChan <- m.Body
+			if c.share != nil {
+				c.share.Write(m.Body)
+			}
+			if done := c.recordingDone(); done != nil {
+				select {
+				case c.terminalOutputChan <- m.Body:
+				case <-done:
+				case <-ctx.Done():
+					return
+				}
 			}
 		} else if m.Header.Proto == ws.ProtoTypeShell &&
 			m.Header.MsgType == wsshell.MessageTypePingShell {
 			if healthcheckTimeout, ok := m.Header.Properties["timeout"].(int64); ok &&
 				healthcheckTimeout > 0 {
-				c.healthcheck <- int(healthcheckTimeout)
+				select {
+				case c.healthcheck <- int(healthcheckTimeout):
+				case <-ctx.Done():
+					return
+				}
 			}
 			m := &ws.ProtoMsg{
 				Header: ws.ProtoHdr{
@@ -553,7 +1026,11 @@ func (c *TerminalCmd) pipeStdout(
 					SessionID: c.sessionID,
 				},
 			}
-			msgChan <- m
+			select {
+			case msgChan <- m:
+			case <-ctx.Done():
+				return
+			}
 		} else if m.Header.Proto == ws.ProtoTypeShell &&
 			m.Header.MsgType == wsshell.MessageTypeSpawnShell {
 			status, ok := m.Header.Properties["status"].(int64)
@@ -0,0 +1,279 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/mendersoftware/mender-cli/log"
+)
+
+const (
+	argFormat = "format"
+
+	formatAsciicast = "asciicast"
+
+	asciicastVersion     = 2
+	asciicastEventOutput = "o"
+	asciicastEventResize = "r"
+	asciicastEventInput  = "i"
+)
+
+var terminalExportCmd = &cobra.Command{
+	Use:   "export [flags] RECORDING_FILE [OUTPUT_FILE]",
+	Short: "Export a terminal recording to another format",
+	Long: "Export a terminal recording saved with --record to another format.\n\n" +
+		"The only supported format is asciicast v2, which can be played with\n" +
+		"asciinema. If OUTPUT_FILE is not given, the result is written to the\n" +
+		"standard output. Files in the asciicast format can be played back\n" +
+		"directly with --playback.",
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(c *cobra.Command, args []string) {
+		cmd, err := NewTerminalExportCmd(c, args)
+		CheckErr(err)
+		CheckErr(cmd.Run())
+	},
+}
+
+func init() {
+	terminalExportCmd.Flags().StringP(argFormat, "f", formatAsciicast,
+		"output format [asciicast]")
+}
+
+// TerminalExportCmd handles the terminal export command
+type TerminalExportCmd struct {
+	recordingFile string
+	outputFile    string
+	format        string
+}
+
+// NewTerminalExportCmd returns a new TerminalExportCmd
+func NewTerminalExportCmd(cmd *cobra.Command, args []string) (*TerminalExportCmd, error) {
+	format, err := cmd.Flags().GetString(argFormat)
+	if err != nil {
+		return nil, err
+	}
+	if format != formatAsciicast {
+		return nil, errors.New("unsupported format: " + format)
+	}
+
+	outputFile := ""
+	if len(args) == 2 {
+		outputFile = args[1]
+	}
+
+	return &TerminalExportCmd{
+		recordingFile: args[0],
+		outputFile:    outputFile,
+		format:        format,
+	}, nil
+}
+
+// Run executes the command
+func (c *TerminalExportCmd) Run() error {
+	f, err := os.Open(c.recordingFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	recording, err := readTerminalRecording(f)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if c.outputFile != "" {
+		out, err := os.OpenFile(c.outputFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		w = out
+	}
+
+	bw := bufio.NewWriter(w)
+	if err = writeAsciicast(bw, recording); err != nil {
+		return err
+	}
+	if err = bw.Flush(); err != nil {
+		return err
+	}
+	if c.outputFile != "" {
+		log.Infof("Exported %s to %s\n", c.recordingFile, c.outputFile)
+	}
+	return nil
+}
+
+type asciicastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Duration  float64           `json:"duration,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// writeAsciicast writes a recording as asciicast v2 JSON lines
+func writeAsciicast(w io.Writer, recording *terminalRecording) error {
+	header := asciicastHeader{
+		Version:   asciicastVersion,
+		Width:     int(recording.Header.TerminalWidth),
+		Height:    int(recording.Header.TerminalHeight),
+		Timestamp: recording.Header.Timestamp,
+		Title:     string(bytes.TrimRight(recording.Header.DeviceID[:], "\x00")),
+	}
+	if n := len(recording.Records); n > 0 {
+		header.Duration = recording.Records[n-1].Offset.Seconds()
+	}
+	if termType := bytes.TrimRight(recording.Header.TerminalType[:], "\x00"); len(termType) > 0 {
+		header.Env = map[string]string{"TERM": string(termType)}
+	}
+	e := json.NewEncoder(w)
+	if err := e.Encode(header); err != nil {
+		return err
+	}
+
+	// output chunks may split multi-byte characters, which are carried
+	// over to the next event to keep the JSON strings valid
+	var pending []byte
+	for _, o := range recording.Records {
+		var event []interface{}
+		switch o.Type {
+		case terminalRecordingOutput:
+			data := append(pending, o.Data...)
+			data, pending = splitIncompleteUTF8(data)
+			if len(data) == 0 {
+				continue
+			}
+			event = []interface{}{o.Offset.Seconds(), asciicastEventOutput, string(data)}
+		case terminalRecordingInput:
+			event = []interface{}{o.Offset.Seconds(), asciicastEventInput,
+				strings.ToValidUTF8(string(o.Data), string(utf8.RuneError))}
+		case terminalRecordingResize:
+			event = []interface{}{o.Offset.Seconds(), asciicastEventResize,
+				fmt.Sprintf("%dx%d", o.TerminalWidth, o.TerminalHeight)}
+		default:
+			continue
+		}
+		if err := e.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitIncompleteUTF8 splits an incomplete UTF-8 sequence at the end of
+// data, if any
+func splitIncompleteUTF8(data []byte) ([]byte, []byte) {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return data[:i], append([]byte(nil), data[i:]...)
+			}
+			break
+		}
+	}
+	return data, nil
+}
+
+// readAsciicast reads an asciicast v2 recording
+func readAsciicast(r io.Reader) (*terminalRecording, error) {
+	d := json.NewDecoder(r)
+	var header asciicastHeader
+	if err := d.Decode(&header); err != nil {
+		return nil, errors.Wrap(err, "Can't read the asciicast header")
+	}
+	if header.Version != asciicastVersion {
+		return nil, errors.Errorf("unsupported asciicast version: %d", header.Version)
+	}
+
+	recording := &terminalRecording{
+		Header: TerminalRecordingHeader{
+			Version:        terminalRecordingVersion,
+			TerminalWidth:  int16(header.Width),
+			TerminalHeight: int16(header.Height),
+			Timestamp:      header.Timestamp,
+		},
+	}
+	copy(recording.Header.DeviceID[:], []byte(header.Title))
+	copy(recording.Header.TerminalType[:], []byte(header.Env["TERM"]))
+
+	for {
+		var event []interface{}
+		err := d.Decode(&event)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "Can't read the asciicast event")
+		}
+		if len(event) != 3 {
+			return nil, errors.Errorf("invalid asciicast event: %v", event)
+		}
+		seconds, ok1 := event[0].(float64)
+		eventType, ok2 := event[1].(string)
+		data, ok3 := event[2].(string)
+		if !ok1 || !ok2 || !ok3 {
+			return nil, errors.Errorf("invalid asciicast event: %v", event)
+		}
+		o := TerminalRecordingData{
+			Offset: time.Duration(seconds * float64(time.Second)),
+		}
+		switch eventType {
+		case asciicastEventOutput:
+			o.Type = terminalRecordingOutput
+			o.Data = []byte(data)
+		case asciicastEventInput:
+			o.Type = terminalRecordingInput
+			o.Data = []byte(data)
+		case asciicastEventResize:
+			o.Type = terminalRecordingResize
+			if _, err := fmt.Sscanf(data, "%dx%d",
+				&o.TerminalWidth, &o.TerminalHeight); err != nil {
+				return nil, errors.Errorf("invalid asciicast resize event: %q", data)
+			}
+		default:
+			continue
+		}
+		recording.Records = append(recording.Records, o)
+	}
+	return recording, nil
+}
+
+// readAnyTerminalRecording reads a recording either in the local recording
+// format or in the asciicast format
+func readAnyTerminalRecording(r io.Reader) (*terminalRecording, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't read the recording")
+	}
+	// the local recording format is gzip compressed
+	if magic[0] == 0x1f && magic[1] == 0x8b {
+		return readTerminalRecording(br)
+	}
+	return readAsciicast(br)
+}
@@ -0,0 +1,270 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+
+	"github.com/mendersoftware/mender-cli/client/deviceconnect"
+)
+
+const (
+	// default escape character, like ssh
+	defaultEscapeChar = "~"
+	escapeCharNone    = "none"
+
+	// escape commands, typed after the escape character at the beginning
+	// of a line
+	escapeCommandDisconnect   = '.'
+	escapeCommandRecord       = 'r'
+	escapeCommandInfo         = 'i'
+	escapeCommandFileTransfer = 'f'
+	escapeCommandHelp         = '?'
+
+	// commands of the file transfer prompt
+	fileTransferCommandUpload   = "upload"
+	fileTransferCommandDownload = "download"
+)
+
+func isEscapeCommand(b byte) bool {
+	switch b {
+	case escapeCommandDisconnect, escapeCommandRecord, escapeCommandInfo,
+		escapeCommandFileTransfer, escapeCommandHelp:
+		return true
+	}
+	return false
+}
+
+// escapeParser finds the escape commands in the terminal input; like in
+// ssh, the escape character is only recognized at the beginning of a line
+type escapeParser struct {
+	escapeChar byte
+	lineStart  bool
+	// pending is set when the escape character was the last input
+	pending bool
+}
+
+// newEscapeParser returns a new escapeParser, which never finds any
+// command if the escape character is zero
+func newEscapeParser(escapeChar byte) *escapeParser {
+	return &escapeParser{
+		escapeChar: escapeChar,
+		lineStart:  true,
+	}
+}
+
+// Next returns the input preceding the first escape command in data, the
+// command, which is zero if there is none, and the data following it
+func (p *escapeParser) Next(data []byte) ([]byte, byte, []byte) {
+	if p.escapeChar == 0 {
+		return data, 0, nil
+	}
+	var input []byte
+	for i, b := range data {
+		if p.pending {
+			p.pending = false
+			if isEscapeCommand(b) {
+				return input, b, data[i+1:]
+			}
+			// the escape character typed twice sends it literally
+			if b != p.escapeChar {
+				input = append(input, p.escapeChar)
+			}
+		} else if p.lineStart && b == p.escapeChar {
+			p.pending = true
+			continue
+		}
+		input = append(input, b)
+		p.lineStart = b == '\r' || b == '\n'
+	}
+	return input, 0, nil
+}
+
+// escapeCommand runs an escape command, returning the input left after
+// reading the command arguments, if any
+func (c *TerminalCmd) escapeCommand(command byte, s *bufio.Reader, rest []byte) []byte {
+	switch command {
+	case escapeCommandDisconnect:
+		c.notify("disconnected")
+		c.Stop()
+	case escapeCommandRecord:
+		c.toggleRecording()
+	case escapeCommandInfo:
+		c.showSessionInfo()
+	case escapeCommandFileTransfer:
+		rest = c.fileTransferPrompt(s, rest)
+	case escapeCommandHelp:
+		esc := string(c.escapeChar)
+		fmt.Fprint(os.Stdout, "\r\nSupported escape sequences:\r\n"+
+			"  "+esc+".  disconnect\r\n"+
+			"  "+esc+"r  start, pause or resume the recording\r\n"+
+			"  "+esc+"i  show the session information\r\n"+
+			"  "+esc+"f  upload or download a file\r\n"+
+			"  "+esc+"?  show this help\r\n"+
+			"  "+esc+esc+"  send the escape character\r\n"+
+			"(Escape sequences are only recognized at the beginning of a line.)\r\n")
+	}
+	return rest
+}
+
+// toggleRecording starts recording the session, or pauses and resumes
+// the recording if already started
+func (c *TerminalCmd) toggleRecording() {
+	if c.recording.Load() {
+		paused := !c.recordingPaused.Load()
+		c.recordingPaused.Store(paused)
+		if paused {
+			c.notify("recording paused")
+		} else {
+			c.notify("recording resumed")
+		}
+		return
+	}
+
+	// don't overwrite the file of a previous recording, nor an existing
+	// file named by --record
+	if _, err := os.Stat(c.recordFile); c.recordFile == "" || err == nil {
+		c.recordFile = newRecordFileName(c.deviceID, time.Now())
+	}
+	termWidth, termHeight := defaultTermWidth, defaultTermHeight
+	if termID := int(os.Stdout.Fd()); term.IsTerminal(termID) {
+		if w, h, err := term.GetSize(termID); err == nil {
+			termWidth, termHeight = w, h
+		}
+	}
+	if err := c.startRecording(termWidth, termHeight); err != nil {
+		c.notify(err.Error())
+		return
+	}
+	c.notify("recording to file: " + c.recordFile)
+}
+
+// newRecordFileName returns the name of a new recording file of a session
+// with the device, which doesn't exist yet
+func newRecordFileName(deviceID string, now time.Time) string {
+	name := fmt.Sprintf("terminal-%s-%s", deviceID, now.Format("20060102-150405"))
+	path := name + ".rec"
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); err != nil {
+			return path
+		}
+		path = fmt.Sprintf("%s-%d.rec", name, i)
+	}
+}
+
+// showSessionInfo shows the device and session IDs and the latency of the
+// connection, measured with the ping-pong health check
+func (c *TerminalCmd) showSessionInfo() {
+	latency := "unknown"
+	if client := c.client.Load(); client != nil {
+		if l := client.Latency(); l > 0 {
+			latency = l.Round(time.Millisecond).String()
+		}
+		// refresh the measurement for the next time
+		_ = client.Ping()
+	}
+	recording := "off"
+	if c.recording.Load() {
+		recording = c.recordFile
+		if c.recordingPaused.Load() {
+			recording += " (paused)"
+		}
+	}
+	fmt.Fprintf(os.Stdout, "\r\nDevice ID: %s\r\nSession ID: %s\r\n"+
+		"Server: %s\r\nLatency: %s\r\nRecording: %s\r\n",
+		c.deviceID, c.sessionID, c.server, latency, recording)
+}
+
+// fileTransferPrompt reads a file transfer command and runs it, returning
+// the input left after reading the command
+func (c *TerminalCmd) fileTransferPrompt(s *bufio.Reader, rest []byte) []byte {
+	fmt.Fprint(os.Stdout, "\r\n"+fileTransferCommandUpload+" LOCAL_PATH REMOTE_PATH | "+
+		fileTransferCommandDownload+" REMOTE_PATH LOCAL_PATH\r\nmender-cli> ")
+	line, rest, ok := readPromptLine(s, rest)
+	if !ok {
+		return rest
+	}
+	args := strings.Fields(line)
+	if len(args) == 0 {
+		return rest
+	} else if len(args) != 3 {
+		c.notify("invalid file transfer command: " + line)
+		return rest
+	}
+
+	client := deviceconnect.NewFileTransferClient(c.server, c.token, c.skipVerify)
+	var err error
+	switch args[0] {
+	case fileTransferCommandUpload:
+		err = client.Upload(args[1], &deviceconnect.DeviceSpec{
+			DeviceID:   c.deviceID,
+			DevicePath: args[2],
+		}, true)
+	case fileTransferCommandDownload:
+		err = client.Download(&deviceconnect.DeviceSpec{
+			DeviceID:   c.deviceID,
+			DevicePath: args[1],
+		}, args[2], true)
+	default:
+		c.notify("unknown file transfer command: " + args[0])
+		return rest
+	}
+	if err != nil {
+		c.notify(fmt.Sprintf("%s failed: %s", args[0], err.Error()))
+	} else {
+		c.notify(args[0] + " completed")
+	}
+	return rest
+}
+
+// readPromptLine reads a line from the terminal in raw mode, echoing it
+// back; CTRL+C cancels the prompt
+func readPromptLine(s *bufio.Reader, rest []byte) (string, []byte, bool) {
+	var line []byte
+	for {
+		var b byte
+		if len(rest) > 0 {
+			b, rest = rest[0], rest[1:]
+		} else {
+			var err error
+			if b, err = s.ReadByte(); err != nil {
+				return "", rest, false
+			}
+		}
+		switch {
+		case b == '\r' || b == '\n':
+			fmt.Fprint(os.Stdout, "\r\n")
+			return string(line), rest, true
+		case b == 0x03:
+			fmt.Fprint(os.Stdout, "^C\r\n")
+			return "", rest, false
+		case b == 0x7f || b == 0x08:
+			if len(line) > 0 {
+				_, size := utf8.DecodeLastRune(line)
+				line = line[:len(line)-size]
+				fmt.Fprint(os.Stdout, "\b \b")
+			}
+		case b >= 0x20:
+			line = append(line, b)
+			_, _ = os.Stdout.Write([]byte{b})
+		}
+	}
+}
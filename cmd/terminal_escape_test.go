@@ -0,0 +1,95 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestEscapeParser(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]struct {
+		escapeChar byte
+		chunks     []string
+		input      string
+		commands   string
+	}{
+		"no escape": {
+			escapeChar: '~',
+			chunks:     []string{"ls ~/foo\r"},
+			input:      "ls ~/foo\r",
+		},
+		"commands at the beginning of lines": {
+			escapeChar: '~',
+			chunks:     []string{"~i", "ls\r~", ".", "~r"},
+			input:      "ls\r",
+			commands:   "i.r",
+		},
+		"literal escape character": {
+			escapeChar: '~',
+			chunks:     []string{"~~i\r~x"},
+			input:      "~i\r~x",
+		},
+		"disabled": {
+			chunks: []string{"~.\r~?"},
+			input:  "~.\r~?",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			p := newEscapeParser(tc.escapeChar)
+			var input, commands []byte
+			for _, chunk := range tc.chunks {
+				for data := []byte(chunk); len(data) > 0; {
+					in, command, rest := p.Next(data)
+					input = append(input, in...)
+					if command != 0 {
+						commands = append(commands, command)
+					}
+					data = rest
+				}
+			}
+			if string(input) != tc.input {
+				t.Errorf("Unexpected input: %q", string(input))
+			}
+			if string(commands) != tc.commands {
+				t.Errorf("Unexpected commands: %q", string(commands))
+			}
+		})
+	}
+}
+
+// TestNewRecordFileName changes the working directory, so it doesn't run in
+// parallel with the other tests
+func TestNewRecordFileName(t *testing.T) {
+	t.Chdir(t.TempDir())
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, expected := range []string{
+		"terminal-device-1-20250102-030405.rec",
+		"terminal-device-1-20250102-030405-2.rec",
+		"terminal-device-1-20250102-030405-3.rec",
+	} {
+		name := newRecordFileName("device-1", now)
+		if name != expected {
+			t.Fatalf("Unexpected name: %s, expected %s", name, expected)
+		}
+		if err := os.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"bytes"
+	"time"
+)
+
+const (
+	// time to wait for the device to echo the input back before
+	// considering the echo disabled
+	inputEchoTimeout = 500 * time.Millisecond
+
+	// replacement for the redacted input characters
+	inputRedactedChar = '*'
+)
+
+// inputRedactor masks the input records which are not echoed back by the
+// device, like passwords typed while the remote echo is disabled.
+//
+// The input records are held back until either the echo is received or
+// the echo timeout expires, together with all the records that follow
+// them, so the records are still released in order.
+type inputRedactor struct {
+	timeout time.Duration
+	queue   []TerminalRecordingData
+	// echo is the output received after the first held back input
+	echo []byte
+}
+
+func newInputRedactor(timeout time.Duration) *inputRedactor {
+	return &inputRedactor{
+		timeout: timeout,
+	}
+}
+
+// Add adds a record, returning the records which can be written
+func (r *inputRedactor) Add(o TerminalRecordingData) []TerminalRecordingData {
+	if len(r.queue) > 0 && o.Type == terminalRecordingOutput {
+		r.echo = append(r.echo, o.Data...)
+	}
+	r.queue = append(r.queue, o)
+	return r.release(o.Offset, false)
+}
+
+// Deadline returns the offset at which the first held back input is
+// redacted, if any
+func (r *inputRedactor) Deadline() (time.Duration, bool) {
+	if len(r.queue) == 0 {
+		return 0, false
+	}
+	return r.queue[0].Offset + r.timeout, true
+}
+
+// Expire redacts the input not echoed back until the given offset,
+// returning the records which can be written
+func (r *inputRedactor) Expire(offset time.Duration) []TerminalRecordingData {
+	return r.release(offset, false)
+}
+
+// Flush redacts all the input not echoed back yet, returning all the
+// records held back
+func (r *inputRedactor) Flush() []TerminalRecordingData {
+	return r.release(0, true)
+}
+
+func (r *inputRedactor) release(offset time.Duration, flush bool) []TerminalRecordingData {
+	var ready []TerminalRecordingData
+	for len(r.queue) > 0 {
+		o := r.queue[0]
+		if o.Type == terminalRecordingInput {
+			text := inputText(o.Data)
+			switch {
+			case len(text) == 0:
+				// control characters and escape sequences are not echoed
+			case bytes.HasPrefix(r.echo, text):
+				r.echo = r.echo[len(text):]
+			case !flush && offset < o.Offset+r.timeout &&
+				len(r.echo) < len(text) && bytes.HasPrefix(text, r.echo):
+				// wait for the rest of the echo
+				return ready
+			default:
+				o.Data = redactInput(o.Data)
+			}
+		} else if o.Type == terminalRecordingOutput {
+			// output preceding the next input can't be its echo
+			later := 0
+			for _, next := range r.queue[1:] {
+				if next.Type == terminalRecordingOutput {
+					later += len(next.Data)
+				}
+			}
+			if len(r.echo) > later {
+				r.echo = r.echo[len(r.echo)-later:]
+			}
+		}
+		ready = append(ready, o)
+		r.queue = r.queue[1:]
+		if len(r.queue) == 0 {
+			r.echo = nil
+		}
+	}
+	return ready
+}
+
+// inputText returns the text preceding the first control character, which
+// is what the device echoes back when the echo is enabled
+func inputText(data []byte) []byte {
+	for i, b := range data {
+		if b < 0x20 || b == 0x7f {
+			return data[:i]
+		}
+	}
+	return data
+}
+
+// redactInput masks all the characters of the input, except the control
+// characters
+func redactInput(data []byte) []byte {
+	redacted := make([]byte, len(data))
+	for i, b := range data {
+		if b < 0x20 || b == 0x7f {
+			redacted[i] = b
+		} else {
+			redacted[i] = inputRedactedChar
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInputRedactor(t *testing.T) {
+	t.Parallel()
+	input := func(data string, offset time.Duration) TerminalRecordingData {
+		return TerminalRecordingData{
+			Type: terminalRecordingInput, Data: []byte(data), Offset: offset,
+		}
+	}
+	output := func(data string, offset time.Duration) TerminalRecordingData {
+		return TerminalRecordingData{
+			Type: terminalRecordingOutput, Data: []byte(data), Offset: offset,
+		}
+	}
+
+	redactor := newInputRedactor(time.Second)
+	var records []TerminalRecordingData
+	for _, o := range []TerminalRecordingData{
+		output("$ ", 0),
+		// echoed input, including input typed faster than the echo
+		input("l", 1*time.Millisecond),
+		input("s", 2*time.Millisecond),
+		output("l", 3*time.Millisecond),
+		output("s", 4*time.Millisecond),
+		input("\r", 5*time.Millisecond),
+		output("\r\nPassword: ", 6*time.Millisecond),
+		// input typed while the echo is disabled
+		input("se", 7*time.Millisecond),
+		input("cret", 8*time.Millisecond),
+		input("\r", 9*time.Millisecond),
+	} {
+		records = append(records, redactor.Add(o)...)
+	}
+	if deadline, ok := redactor.Deadline(); !ok || deadline != time.Second+7*time.Millisecond {
+		t.Errorf("Unexpected deadline: %s", deadline)
+	}
+	records = append(records, redactor.Add(output("\r\n$ ", 10*time.Millisecond))...)
+
+	// input which is not echoed before the timeout
+	records = append(records, redactor.Add(input("x", 3*time.Second))...)
+	if ready := redactor.Expire(3*time.Second + time.Millisecond); len(ready) > 0 {
+		t.Errorf("Unexpected records before the timeout: %v", ready)
+	}
+	records = append(records, redactor.Expire(4*time.Second)...)
+	if ready := redactor.Flush(); len(ready) > 0 {
+		t.Errorf("Unexpected records after flushing: %v", ready)
+	}
+
+	expected := []string{
+		"$ ", "l", "s", "l", "s", "\r", "\r\nPassword: ",
+		"**", "****", "\r", "\r\n$ ", "*",
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("Expected %d records, got %d", len(expected), len(records))
+	}
+	for i, o := range records {
+		if string(o.Data) != expected[i] {
+			t.Errorf("Unexpected record %d: %q", i, string(o.Data))
+		}
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/gob"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// playback seek step
+	playbackSeekStep = 5 * time.Second
+
+	// playback speed limits
+	playbackMinSpeed = 1.0 / 16
+	playbackMaxSpeed = 16.0
+
+	// terminal reset escape sequence, used when seeking backwards
+	terminalReset = "\x1bc"
+)
+
+// playback control keys
+const (
+	playbackKeyNone = iota
+	playbackKeyPause
+	playbackKeyStep
+	playbackKeyFaster
+	playbackKeySlower
+	playbackKeyForward
+	playbackKeyBackward
+	playbackKeyQuit
+)
+
+// terminalRecording is a terminal recording loaded in memory
+type terminalRecording struct {
+	Header  TerminalRecordingHeader
+	Records []TerminalRecordingData
+}
+
+// readTerminalRecording reads a recording in the local recording format
+func readTerminalRecording(r io.Reader) (*terminalRecording, error) {
+	fz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer fz.Close()
+
+	recording := &terminalRecording{}
+	err = binary.Read(fz, binary.LittleEndian, &recording.Header)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't read header")
+	}
+	if recording.Header.Version > terminalRecordingVersion {
+		return nil, errors.Errorf("unsupported recording version: %d",
+			recording.Header.Version)
+	}
+
+	d := gob.NewDecoder(fz)
+	for {
+		var o TerminalRecordingData
+		err = d.Decode(&o)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "Decoding error")
+		}
+		// version 1 recordings have no timing information
+		if recording.Header.Version < 2 {
+			o.Offset = time.Duration(len(recording.Records)) * playbackSleep
+		}
+		recording.Records = append(recording.Records, o)
+	}
+	return recording, nil
+}
+
+// writeTerminalRecording writes a recording in the local recording format
+func writeTerminalRecording(w io.Writer, recording *terminalRecording) error {
+	fz := gzip.NewWriter(w)
+	header := recording.Header
+	header.Version = terminalRecordingVersion
+	if err := binary.Write(fz, binary.LittleEndian, header); err != nil {
+		return errors.Wrap(err, "Header write failed")
+	}
+	e := gob.NewEncoder(fz)
+	for _, o := range recording.Records {
+		if err := e.Encode(o); err != nil {
+			return errors.Wrap(err, "Encoding error")
+		}
+	}
+	return fz.Close()
+}
+
+// terminalPlayer plays back a terminal recording
+type terminalPlayer struct {
+	recording *terminalRecording
+	out       io.Writer
+	speed     float64
+	// maxIdle limits the time between two records, if not zero
+	maxIdle time.Duration
+
+	// offsets are the record offsets with the idle time limit applied
+	offsets []time.Duration
+	// pos is the index of the next record to play
+	pos int
+	// clock is the current position in the recording timeline
+	clock  time.Duration
+	paused bool
+}
+
+func newTerminalPlayer(
+	recording *terminalRecording,
+	out io.Writer,
+	speed float64,
+	maxIdle time.Duration,
+) *terminalPlayer {
+	offsets := make([]time.Duration, len(recording.Records))
+	var prev time.Duration
+	for i, o := range recording.Records {
+		gap := o.Offset - prev
+		if gap < 0 {
+			gap = 0
+		} else if maxIdle > 0 && gap > maxIdle {
+			gap = maxIdle
+		}
+		if i > 0 {
+			offsets[i] = offsets[i-1] + gap
+		} else {
+			offsets[i] = gap
+		}
+		prev = o.Offset
+	}
+	return &terminalPlayer{
+		recording: recording,
+		out:       out,
+		speed:     speed,
+		maxIdle:   maxIdle,
+		offsets:   offsets,
+	}
+}
+
+// Duration returns the duration of the playback at normal speed
+func (p *terminalPlayer) Duration() time.Duration {
+	if len(p.offsets) == 0 {
+		return 0
+	}
+	return p.offsets[len(p.offsets)-1]
+}
+
+// Play plays back the recording until the end, reading the control keys
+// from the keys channel, which may be nil
+func (p *terminalPlayer) Play(ctx context.Context, keys <-chan int) error {
+	for p.pos < len(p.recording.Records) {
+		var timer <-chan time.Time
+		start := time.Now()
+		if !p.paused {
+			wait := time.Duration(float64(p.offsets[p.pos]-p.clock) / p.speed)
+			timer = time.After(wait)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer:
+			if err := p.step(); err != nil {
+				return err
+			}
+		case key := <-keys:
+			if !p.paused {
+				p.clock += time.Duration(float64(time.Since(start)) * p.speed)
+			}
+			switch key {
+			case playbackKeyPause:
+				p.paused = !p.paused
+			case playbackKeyStep:
+				if p.paused {
+					if err := p.step(); err != nil {
+						return err
+					}
+				}
+			case playbackKeyFaster:
+				p.speed = min(p.speed*2, playbackMaxSpeed)
+			case playbackKeySlower:
+				p.speed = max(p.speed/2, playbackMinSpeed)
+			case playbackKeyForward:
+				if err := p.seek(p.clock + playbackSeekStep); err != nil {
+					return err
+				}
+			case playbackKeyBackward:
+				if err := p.seek(p.clock - playbackSeekStep); err != nil {
+					return err
+				}
+			case playbackKeyQuit:
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// step plays the next record
+func (p *terminalPlayer) step() error {
+	o := p.recording.Records[p.pos]
+	if o.Type == terminalRecordingOutput {
+		if _, err := p.out.Write(o.Data); err != nil {
+			return errors.Wrap(err, "Writing error")
+		}
+	}
+	p.clock = p.offsets[p.pos]
+	p.pos++
+	return nil
+}
+
+// seek moves the playback to the given position of the timeline, replaying
+// the recording from the start when seeking backwards
+func (p *terminalPlayer) seek(clock time.Duration) error {
+	if clock < 0 {
+		clock = 0
+	}
+	if clock < p.clock {
+		if _, err := io.WriteString(p.out, terminalReset); err != nil {
+			return errors.Wrap(err, "Writing error")
+		}
+		p.pos = 0
+	}
+	// render all the records up to the new position at once
+	var buf bytes.Buffer
+	for ; p.pos < len(p.offsets) && p.offsets[p.pos] <= clock; p.pos++ {
+		if o := p.recording.Records[p.pos]; o.Type == terminalRecordingOutput {
+			buf.Write(o.Data)
+		}
+	}
+	p.clock = clock
+	if _, err := p.out.Write(buf.Bytes()); err != nil {
+		return errors.Wrap(err, "Writing error")
+	}
+	return nil
+}
+
+// readPlaybackKeys reads the playback control keys from r
+func readPlaybackKeys(ctx context.Context, r io.Reader, keys chan<- int) {
+	buf := make([]byte, 16)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+		key := playbackKeyNone
+		switch s := string(buf[:n]); s {
+		case " ", "p":
+			key = playbackKeyPause
+		case ".", "n":
+			key = playbackKeyStep
+		case "+", "=":
+			key = playbackKeyFaster
+		case "-":
+			key = playbackKeySlower
+		case "\x1b[C", "l":
+			key = playbackKeyForward
+		case "\x1b[D", "h":
+			key = playbackKeyBackward
+		case "q", "\x03", "\x1d":
+			key = playbackKeyQuit
+		}
+		if key == playbackKeyNone {
+			continue
+		}
+		select {
+		case keys <- key:
+		case <-ctx.Done():
+			return
+		}
+		if key == playbackKeyQuit {
+			return
+		}
+	}
+}
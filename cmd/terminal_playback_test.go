@@ -0,0 +1,146 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/gob"
+	"testing"
+	"time"
+)
+
+func TestReadTerminalRecordingV1(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	fz := gzip.NewWriter(&buf)
+	_ = binary.Write(fz, binary.LittleEndian, TerminalRecordingHeader{Version: 1})
+	// version 1 records only had the type and the data
+	e := gob.NewEncoder(fz)
+	for _, data := range []string{"foo", "bar", "baz"} {
+		err := e.Encode(struct {
+			Type TerminalRecordingType
+			Data []byte
+		}{terminalRecordingOutput, []byte(data)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	fz.Close()
+
+	recording, err := readTerminalRecording(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if len(recording.Records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(recording.Records))
+	}
+	if recording.Records[2].Offset != 2*playbackSleep {
+		t.Errorf("Unexpected offset: %s", recording.Records[2].Offset)
+	}
+}
+
+func TestTerminalPlayer(t *testing.T) {
+	t.Parallel()
+	recording := &terminalRecording{
+		Header: TerminalRecordingHeader{Version: terminalRecordingVersion},
+		Records: []TerminalRecordingData{
+			{Type: terminalRecordingOutput, Data: []byte("a"), Offset: 0},
+			{Type: terminalRecordingResize, TerminalWidth: 100, TerminalHeight: 50,
+				Offset: time.Millisecond},
+			{Type: terminalRecordingOutput, Data: []byte("b"), Offset: 2 * time.Millisecond},
+			{Type: terminalRecordingOutput, Data: []byte("c"), Offset: time.Hour},
+		},
+	}
+	var buf bytes.Buffer
+	if err := writeTerminalRecording(&buf, recording); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	recording, err := readTerminalRecording(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	var out bytes.Buffer
+	player := newTerminalPlayer(recording, &out, 1, 10*time.Millisecond)
+	if player.Duration() != 12*time.Millisecond {
+		t.Errorf("Unexpected duration: %s", player.Duration())
+	}
+	if err := player.Play(context.Background(), nil); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if out.String() != "abc" {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+
+	out.Reset()
+	if err := player.seek(time.Millisecond); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if out.String() != terminalReset+"a" || player.pos != 2 {
+		t.Errorf("Unexpected output after seeking: %q (position %d)", out.String(), player.pos)
+	}
+}
+
+func TestAsciicast(t *testing.T) {
+	t.Parallel()
+	recording := &terminalRecording{
+		Header: TerminalRecordingHeader{
+			Version:        terminalRecordingVersion,
+			TerminalWidth:  80,
+			TerminalHeight: 24,
+			Timestamp:      1700000000,
+		},
+		Records: []TerminalRecordingData{
+			{Type: terminalRecordingOutput, Data: []byte("caf\xc3"), Offset: time.Second},
+			{Type: terminalRecordingOutput, Data: []byte("\xa9\r\n"),
+				Offset: 2 * time.Second},
+			{Type: terminalRecordingResize, TerminalWidth: 100, TerminalHeight: 50,
+				Offset: 3 * time.Second},
+		},
+	}
+	copy(recording.Header.DeviceID[:], "1234")
+	copy(recording.Header.TerminalType[:], terminalTypeDefault)
+
+	var buf bytes.Buffer
+	if err := writeAsciicast(&buf, recording); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	expected := `{"version":2,"width":80,"height":24,"timestamp":1700000000,` +
+		`"duration":3,"title":"1234","env":{"TERM":"xterm-256color"}}
+[1,"o","caf"]
+[2,"o","é\r\n"]
+[3,"r","100x50"]
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected output: %s", buf.String())
+	}
+
+	imported, err := readAnyTerminalRecording(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if imported.Header.TerminalWidth != 80 || imported.Header.Timestamp != 1700000000 {
+		t.Errorf("Unexpected header: %+v", imported.Header)
+	}
+	if len(imported.Records) != 3 ||
+		string(imported.Records[1].Data) != "é\r\n" ||
+		imported.Records[2].TerminalWidth != 100 ||
+		imported.Records[2].Offset != 3*time.Second {
+		t.Errorf("Unexpected records: %+v", imported.Records)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+const (
+	shareModeReadOnly  = "read-only"
+	shareModeReadWrite = "read-write"
+
+	// number of output chunks buffered for each attached client; slower
+	// clients are disconnected
+	shareClientBuffer = 256
+)
+
+// terminalShare exposes a terminal session to other local mender-cli
+// instances over a Unix socket
+type terminalShare struct {
+	listener  net.Listener
+	readWrite bool
+	banner    string
+	// input is called with the input of the attached clients, in
+	// read-write mode
+	input func([]byte)
+	// notify is called when clients attach and detach
+	notify func(string)
+
+	mutex   sync.Mutex
+	clients map[*shareClient]struct{}
+	closed  bool
+}
+
+type shareClient struct {
+	conn   net.Conn
+	output chan []byte
+}
+
+// newTerminalShare listens on the Unix socket at path, which is only
+// accessible by the current user and the allowed users and groups
+func newTerminalShare(
+	path string,
+	allowed *unixAllowList,
+	readWrite bool,
+	banner string,
+	input func([]byte),
+	notify func(string),
+) (*terminalShare, error) {
+	listener, err := listenUnixPrivate(path, allowed)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to share the session")
+	}
+	return &terminalShare{
+		listener:  listener,
+		readWrite: readWrite,
+		banner:    banner,
+		input:     input,
+		notify:    notify,
+		clients:   map[*shareClient]struct{}{},
+	}, nil
+}
+
+// Serve accepts the clients until the share is closed
+func (s *terminalShare) Serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		client := &shareClient{
+			conn:   conn,
+			output: make(chan []byte, shareClientBuffer),
+		}
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			conn.Close()
+			return
+		}
+		s.clients[client] = struct{}{}
+		count := len(s.clients)
+		s.mutex.Unlock()
+
+		s.notify(fmt.Sprintf("a client attached to the shared session (%d attached)", count))
+		go s.serveClient(client)
+	}
+}
+
+func (s *terminalShare) serveClient(client *shareClient) {
+	defer s.remove(client)
+
+	// read the input, which is discarded in read-only mode, until the
+	// client detaches
+	go func() {
+		defer client.conn.Close()
+		buf := make([]byte, 1024)
+		for {
+			n, err := client.conn.Read(buf)
+			if err != nil {
+				return
+			}
+			if s.readWrite && n > 0 {
+				s.input(append([]byte(nil), buf[:n]...))
+			}
+		}
+	}()
+
+	if _, err := io.WriteString(client.conn, s.banner); err != nil {
+		return
+	}
+	for data := range client.output {
+		if _, err := client.conn.Write(data); err != nil {
+			return
+		}
+	}
+}
+
+func (s *terminalShare) remove(client *shareClient) {
+	client.conn.Close()
+	s.mutex.Lock()
+	_, ok := s.clients[client]
+	if ok {
+		delete(s.clients, client)
+		close(client.output)
+	}
+	count := len(s.clients)
+	closed := s.closed
+	s.mutex.Unlock()
+	if ok && !closed {
+		s.notify(fmt.Sprintf("a client detached from the shared session (%d attached)", count))
+	}
+}
+
+// Write sends the terminal output to all the attached clients
+func (s *terminalShare) Write(data []byte) {
+	s.mutex.Lock()
+	var slow []*shareClient
+	for client := range s.clients {
+		select {
+		case client.output <- data:
+		default:
+			slow = append(slow, client)
+		}
+	}
+	s.mutex.Unlock()
+	for _, client := range slow {
+		s.remove(client)
+	}
+}
+
+// Close disconnects all the clients and removes the socket
+func (s *terminalShare) Close() {
+	s.mutex.Lock()
+	s.closed = true
+	clients := make([]*shareClient, 0, len(s.clients))
+	for client := range s.clients {
+		clients = append(clients, client)
+	}
+	s.mutex.Unlock()
+	s.listener.Close()
+	for _, client := range clients {
+		s.remove(client)
+	}
+}
+
+// attach attaches to a session shared by another mender-cli instance
+func (c *TerminalCmd) attach() error {
+	conn, err := net.Dial("unix", c.attachSocket)
+	if err != nil {
+		return errors.Wrap(err, "Unable to attach to the shared session")
+	}
+	defer conn.Close()
+
+	termID := int(os.Stdout.Fd())
+	if term.IsTerminal(termID) {
+		fmt.Fprintln(os.Stderr, "Press CTRL+] to detach from the session")
+		oldState, err := term.MakeRaw(termID)
+		if err != nil {
+			return errors.Wrap(err, "Unable to set the terminal in raw mode")
+		}
+		defer func() {
+			_ = term.Restore(termID, oldState)
+		}()
+	}
+
+	done := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(os.Stdout, conn)
+		done <- err
+	}()
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				done <- nil
+				return
+			}
+			// CTRL+] detaches from the session
+			if buf[0] == 29 {
+				done <- nil
+				return
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				done <- err
+				return
+			}
+		}
+	}()
+	err = <-done
+	fmt.Fprint(os.Stdout, "\r\n")
+	return err
+}
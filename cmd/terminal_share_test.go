@@ -0,0 +1,84 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package cmd
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTerminalShare(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "share.sock")
+	input := make(chan []byte, 1)
+	attached := make(chan string, 1)
+	share, err := newTerminalShare(path, nil, true, "banner\r\n",
+		func(data []byte) { input <- data },
+		func(msg string) {
+			select {
+			case attached <- msg:
+			default:
+			}
+		})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	go share.Serve()
+
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("Unexpected socket permissions: %v, %v", fi, err)
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	<-attached
+
+	share.Write([]byte("output"))
+	buf := make([]byte, len("banner\r\noutput"))
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if string(buf) != "banner\r\noutput" {
+		t.Errorf("Unexpected output: %q", string(buf))
+	}
+
+	if _, err := conn.Write([]byte("ls\r")); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	select {
+	case data := <-input:
+		if string(data) != "ls\r" {
+			t.Errorf("Unexpected input: %q", string(data))
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Timeout waiting for the input")
+	}
+
+	share.Close()
+	if _, err := conn.Read(buf); err != io.EOF {
+		t.Errorf("Expected EOF after closing, got: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the socket to be removed, got: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+
+	"github.com/mendersoftware/mender-cli/log"
+)
+
+// unixPeerCred are the credentials of the process connected to a Unix
+// socket
+type unixPeerCred struct {
+	uid int
+	gid int
+}
+
+// unixAllowList holds the users and groups allowed to connect to a Unix
+// socket besides the current user
+type unixAllowList struct {
+	uids map[int]bool
+	gids map[int]bool
+}
+
+// parseUnixAllowList parses the users and groups in the form user:USER or
+// group:GROUP, with their names or numeric IDs; it returns nil if there
+// are none
+func parseUnixAllowList(values []string) (*unixAllowList, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	a := &unixAllowList{uids: map[int]bool{}, gids: map[int]bool{}}
+	for _, value := range values {
+		kind, name, _ := strings.Cut(value, ":")
+		if kind != "user" && kind != "group" {
+			return nil, errors.Errorf("invalid %q, expected user:USER or group:GROUP", value)
+		}
+		id, err := strconv.Atoi(name)
+		if err != nil {
+			id, err = lookupUnixID(kind, name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if kind == "user" {
+			a.uids[id] = true
+		} else {
+			a.gids[id] = true
+		}
+	}
+	return a, nil
+}
+
+// lookupUnixID returns the ID of the user or group with the name
+func lookupUnixID(kind, name string) (int, error) {
+	var id string
+	if kind == "user" {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return -1, errors.Wrapf(err, "Unknown user %q", name)
+		}
+		id = u.Uid
+	} else {
+		g, err := user.LookupGroup(name)
+		if err != nil {
+			return -1, errors.Wrapf(err, "Unknown group %q", name)
+		}
+		id = g.Gid
+	}
+	return strconv.Atoi(id)
+}
+
+// allows tells whether the peer is one of the allowed users, or a member
+// of one of the allowed groups, either as its group or as a supplementary
+// group of its user
+func (a *unixAllowList) allows(cred unixPeerCred) bool {
+	if a == nil {
+		return false
+	} else if a.uids[cred.uid] || a.gids[cred.gid] {
+		return true
+	} else if len(a.gids) == 0 {
+		return false
+	}
+	u, err := user.LookupId(strconv.Itoa(cred.uid))
+	if err != nil {
+		return false
+	}
+	groups, err := u.GroupIds()
+	if err != nil {
+		return false
+	}
+	for _, group := range groups {
+		if gid, err := strconv.Atoi(group); err == nil && a.gids[gid] {
+			return true
+		}
+	}
+	return false
+}
+
+// listenUnixPrivate listens on a Unix socket at path which only the current
+// user, and the users and groups of allowed if not nil, can connect to.
+// The socket is never accessible by other users while it is created, and
+// the connections from the processes of the users which aren't allowed
+// are rejected based on their credentials.
+func listenUnixPrivate(path string, allowed *unixAllowList) (net.Listener, error) {
+	// the umask is process-wide, but the socket must not be accessible by
+	// other users between its creation and the chmod
+	mask := unix.Umask(0077)
+	listener, err := net.Listen("unix", path)
+	unix.Umask(mask)
+	if err != nil {
+		return nil, err
+	}
+	// connecting requires the write permission; the other users are
+	// checked when accepting their connections
+	mode := os.FileMode(0600)
+	if allowed != nil {
+		mode = 0666
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return &privateUnixListener{
+		Listener: listener,
+		uid:      unix.Getuid(),
+		allowed:  allowed,
+	}, nil
+}
+
+// privateUnixListener accepts only the connections from the processes of
+// the user uid and of the allowed users
+type privateUnixListener struct {
+	net.Listener
+	uid     int
+	allowed *unixAllowList
+}
+
+func (l *privateUnixListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		cred, err := unixPeerCredentials(conn)
+		if err != nil {
+			log.Verbf("rejected a connection on %s: %s", l.Addr(), err.Error())
+			conn.Close()
+			continue
+		} else if cred.uid != l.uid && !l.allowed.allows(cred) {
+			log.Verbf("rejected a connection on %s from the user %d, group %d",
+				l.Addr(), cred.uid, cred.gid)
+			conn.Close()
+			continue
+		}
+		return conn, nil
+	}
+}
+
+// unixPeerCredentials returns the credentials of the process connected to
+// the Unix socket conn
+func unixPeerCredentials(conn net.Conn) (unixPeerCred, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return unixPeerCred{}, unix.EINVAL
+	}
+	rawConn, err := unixConn.SyscallConn()
+	if err != nil {
+		return unixPeerCred{}, err
+	}
+	var cred unixPeerCred
+	var sockoptErr error
+	err = rawConn.Control(func(fd uintptr) {
+		cred, sockoptErr = getsockoptPeerCred(int(fd))
+	})
+	if err != nil {
+		return unixPeerCred{}, err
+	}
+	return cred, sockoptErr
+}
@@ -0,0 +1,28 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// getsockoptPeerCred returns the credentials of the peer of the Unix
+// socket fd
+func getsockoptPeerCred(fd int) (unixPeerCred, error) {
+	cred, err := unix.GetsockoptUcred(fd, unix.SOL_SOCKET, unix.SO_PEERCRED)
+	if err != nil {
+		return unixPeerCred{}, err
+	}
+	return unixPeerCred{uid: int(cred.Uid), gid: int(cred.Gid)}, nil
+}
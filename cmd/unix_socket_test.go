@@ -0,0 +1,116 @@
+// Copyright 2025 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestListenUnixPrivate(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "private.sock")
+	listener, err := listenUnixPrivate(path, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	defer listener.Close()
+
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("Unexpected socket permissions: %v, %v", fi, err)
+	}
+
+	client, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	defer client.Close()
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	defer conn.Close()
+
+	cred, err := unixPeerCredentials(conn)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if cred.uid != os.Getuid() || cred.gid != os.Getgid() {
+		t.Errorf("Unexpected peer credentials: %+v, expected %d:%d",
+			cred, os.Getuid(), os.Getgid())
+	}
+}
+
+func TestUnixAllowList(t *testing.T) {
+	t.Parallel()
+	allowed, err := parseUnixAllowList([]string{"user:1001", "group:2001"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	testCases := map[string]struct {
+		cred    unixPeerCred
+		allowed bool
+	}{
+		"allowed user": {
+			cred:    unixPeerCred{uid: 1001, gid: 1001},
+			allowed: true,
+		},
+		"allowed group": {
+			cred:    unixPeerCred{uid: 1002, gid: 2001},
+			allowed: true,
+		},
+		"other user": {
+			cred: unixPeerCred{uid: 1003, gid: 1003},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if allowed.allows(tc.cred) != tc.allowed {
+				t.Errorf("Expected allowed to be %v", tc.allowed)
+			}
+		})
+	}
+
+	if allowed, err := parseUnixAllowList(nil); err != nil || allowed != nil {
+		t.Errorf("Unexpected allow list: %v, %v", allowed, err)
+	}
+	for _, value := range []string{"1001", "host:foo", "user:no-such-user-here"} {
+		if _, err := parseUnixAllowList([]string{value}); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
+
+func TestListenUnixPrivateAllowed(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "shared.sock")
+	allowed, err := parseUnixAllowList([]string{"group:" + strconv.Itoa(os.Getgid())})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	listener, err := listenUnixPrivate(path, allowed)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	defer listener.Close()
+
+	// the other users need the write permission to connect
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0666 {
+		t.Errorf("Unexpected socket permissions: %v, %v", fi, err)
+	}
+}
@@ -14,7 +14,9 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/user"
 	"path"
@@ -113,3 +115,10 @@ func getAuthToken(cmd *cobra.Command) (string, error) {
 	tokenValue = strings.TrimSpace(string(token))
 	return tokenValue, nil
 }
+
+// printJSON writes the raw mode (json) output of the commands
+func printJSON(out io.Writer, v interface{}) error {
+	e := json.NewEncoder(out)
+	e.SetIndent("", "  ")
+	return e.Encode(v)
+}